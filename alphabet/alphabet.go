@@ -0,0 +1,130 @@
+package alphabet
+
+import (
+	"fmt"
+	"strings"
+)
+
+/*
+	Molecule alphabets used to guess the type of the sequences and to
+	validate their characters (case insensitive).
+*/
+
+// Alphabet structure
+type Alphabet struct {
+	name    string
+	letters string
+	valid   [256]bool
+}
+
+// Generate a new alphabet from its (upper case) letters
+func New(name, letters string) *Alphabet {
+	a := Alphabet{
+		name:    name,
+		letters: letters,
+	}
+	for _, b := range []byte(letters) {
+		a.valid[b] = true
+		if b >= 'A' && b <= 'Z' {
+			a.valid[b+'a'-'A'] = true
+		}
+	}
+	return &a
+}
+
+// Pre-defined alphabets
+var (
+	DNA        = New("dna", "ACGT")
+	RNA        = New("rna", "ACGU")
+	IUPAC      = New("iupac", "ACGTURYSWKMBDHVN-")
+	Protein    = New("protein", "ACDEFGHIKLMNPQRSTVWY")
+	ProteinExt = New("protein-ext", "ACDEFGHIKLMNPQRSTVWYBZJXUO*-")
+	// Any letter, gaps and stop (default check of the readers)
+	Any = New("any", "ABCDEFGHIJKLMNOPQRSTUVWXYZ*-.")
+)
+
+// Alphabets tried (in this order) by Guess
+var guessOrder = []*Alphabet{DNA, RNA, IUPAC, Protein, ProteinExt}
+
+// Get a pre-defined alphabet from its name
+func ByName(name string) (*Alphabet, bool) {
+	for _, a := range append(guessOrder, Any) {
+		if a.name == strings.ToLower(name) {
+			return a, true
+		}
+	}
+	return nil, false
+}
+
+// Alphabet name
+func (a *Alphabet) String() string {
+	return a.name
+}
+
+// Alphabet letters (upper case)
+func (a *Alphabet) Letters() string {
+	return a.letters
+}
+
+// Return true if the character belongs to the alphabet
+func (a *Alphabet) IsValid(b byte) bool {
+	return a.valid[b]
+}
+
+// Return the index of the first invalid character (-1 if none)
+func (a *Alphabet) Index(s []byte) int {
+	for i, b := range s {
+		if !a.valid[b] {
+			return i
+		}
+	}
+	return -1
+}
+
+// Check all the characters of a sequence
+func (a *Alphabet) Validate(s []byte) error {
+	if i := a.Index(s); i >= 0 {
+		return &InvalidError{
+			Alphabet: a,
+			Char:     s[i],
+			Pos:      i + 1,
+		}
+	}
+	return nil
+}
+
+// Replace the invalid characters (in place), return the number of
+// replacements
+func (a *Alphabet) Replace(s []byte, r byte) int {
+	n := 0
+	for i, b := range s {
+		if !a.valid[b] {
+			s[i] = r
+			n++
+		}
+	}
+	return n
+}
+
+// Guess the alphabet of a sequence (the smallest matching alphabet, nil if
+// none matches)
+func Guess(s []byte) *Alphabet {
+	for _, a := range guessOrder {
+		if a.Index(s) < 0 {
+			return a
+		}
+	}
+	return nil
+}
+
+// Invalid character error
+// NOTE: Pos is the 1-based position in the sequence
+type InvalidError struct {
+	Alphabet *Alphabet
+	Char     byte
+	Pos      int
+}
+
+func (e *InvalidError) Error() string {
+	return fmt.Sprintf("Invalid character %q at position %d (%s alphabet)", e.Char, e.Pos, e.Alphabet)
+}
@@ -0,0 +1,120 @@
+package alphabet
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestIsValid(t *testing.T) {
+	tests := []struct {
+		a              *Alphabet
+		valid, invalid string
+	}{
+		{DNA, "ACGTacgt", "UuNn-*. "},
+		{RNA, "ACGUacgu", "TtNn-"},
+		{IUPAC, "ACGTURYSWKMBDHVNacgturyswkmbdhvn-", "EFIJLOPQXZ*. efx"},
+		{Protein, "ACDEFGHIKLMNPQRSTVWYacdefghiklmnpqrstvwy", "BJOUXZ*-bx"},
+		{ProteinExt, "BZJXUObzjxuo*-", ".@ "},
+		{Any, "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz*-.", "0@ \t\r\n"},
+	}
+	for _, tt := range tests {
+		for _, b := range []byte(tt.valid) {
+			if !tt.a.IsValid(b) {
+				t.Errorf("%s: %q is not valid", tt.a, b)
+			}
+		}
+		for _, b := range []byte(tt.invalid) {
+			if tt.a.IsValid(b) {
+				t.Errorf("%s: %q is valid", tt.a, b)
+			}
+		}
+	}
+
+	// Only the upper case letters are duplicated
+	a := New("test", "Ab1")
+	if !a.IsValid('A') || !a.IsValid('a') || !a.IsValid('b') || a.IsValid('B') || !a.IsValid('1') || a.Letters() != "Ab1" {
+		t.Errorf("New(Ab1) = %v", a.valid)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		a    *Alphabet
+		seq  string
+		char byte
+		pos  int
+	}{
+		{DNA, "", 0, 0},
+		{DNA, "ACGTacgt", 0, 0},
+		{DNA, "NACGT", 'N', 1},
+		{DNA, "ACGTN", 'N', 5},
+		{DNA, "ACuGU", 'u', 3},
+		{Protein, "MKLV*", '*', 5},
+		{Any, "ACGT\r", '\r', 5},
+	}
+	for _, tt := range tests {
+		err := tt.a.Validate([]byte(tt.seq))
+		i := tt.a.Index([]byte(tt.seq))
+		if tt.pos == 0 {
+			if err != nil || i != -1 {
+				t.Errorf("%s: Validate(%q) = %v, Index = %d", tt.a, tt.seq, err, i)
+			}
+			continue
+		}
+		var e *InvalidError
+		if !errors.As(err, &e) || e.Char != tt.char || e.Pos != tt.pos || e.Alphabet != tt.a || i != tt.pos-1 {
+			t.Errorf("%s: Validate(%q) = %v, Index = %d, want %q at %d", tt.a, tt.seq, err, i, tt.char, tt.pos)
+		}
+	}
+}
+
+func TestReplace(t *testing.T) {
+	tests := []struct {
+		a   *Alphabet
+		seq string
+		r   byte
+		out string
+		n   int
+	}{
+		{DNA, "ACGT", 'N', "ACGT", 0},
+		{DNA, "ACRYGT", 'N', "ACNNGT", 2},
+		{DNA, "xacgu", 'N', "NacgN", 2},
+		{IUPAC, "AC?G T", 'N', "ACNGNT", 2},
+		{Protein, "MK*", 'X', "MKX", 1},
+	}
+	for _, tt := range tests {
+		s := []byte(tt.seq)
+		if n := tt.a.Replace(s, tt.r); string(s) != tt.out || n != tt.n {
+			t.Errorf("%s: Replace(%q) = %q, %d, want %q, %d", tt.a, tt.seq, s, n, tt.out, tt.n)
+		}
+	}
+}
+
+func TestGuess(t *testing.T) {
+	tests := []struct {
+		seq string
+		a   *Alphabet
+	}{
+		{"ACGTacgt", DNA},
+		{"", DNA},
+		{"ACGUacgu", RNA},
+		{"ACGTNRY-", IUPAC},
+		{"MKLVEFQ", Protein},
+		{"MKLVX*", ProteinExt},
+		{"ACGT1", nil},
+	}
+	for _, tt := range tests {
+		if a := Guess([]byte(tt.seq)); a != tt.a {
+			t.Errorf("Guess(%q) = %v, want %v", tt.seq, a, tt.a)
+		}
+	}
+	names := map[string]*Alphabet{"dna": DNA, "RNA": RNA, "Protein-Ext": ProteinExt, "any": Any}
+	for name, want := range names {
+		if a, ok := ByName(name); !ok || a != want {
+			t.Errorf("ByName(%s) = %v, %v", name, a, ok)
+		}
+	}
+	if _, ok := ByName("amino"); ok {
+		t.Errorf("ByName(amino): found")
+	}
+}
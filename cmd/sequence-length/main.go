@@ -16,23 +16,23 @@ func check(e error) {
 func main() {
 	// Retrieve argument values
 	input := flag.String("input", "STDIN", "Input fasta file")
-	format := flag.String("format", "fasta", "Input format.")
-	gunzip := flag.Bool("d", false, "Decompress the input (gz).")
+	format := flag.String("format", "auto", "Input format (auto: detected from the input).")
 	flag.Parse()
 
 	if *input == "" {
 		panic("You must provide an input fasta file.")
 	}
 
-	// Open sequence file
-	seqIn := seqio.NewReader(*input, *format, *gunzip)
-	seqIn.CheckPanic()
+	// Open sequence file (the compression is detected from the input)
+	// NOTE: sequences are not retained, the reader memory can be reused
+	seqIn, err := seqio.Open(*input, *format, seqio.WithReuse())
+	check(err)
 	defer seqIn.Close()
 
 	for seqIn.Next() {
-		seqIn.CheckPanic()
 		s := seqIn.Seq()
 
 		fmt.Printf("%s\t%d\n", s.Id, s.Length())
 	}
+	check(seqIn.Err())
 }
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/hdevillers/go-seq/alphabet"
+	"github.com/hdevillers/go-seq/orf"
+	"github.com/hdevillers/go-seq/seq"
+	"github.com/hdevillers/go-seq/seqio"
+	"github.com/hdevillers/go-seq/translate"
+)
+
+func check(e error) {
+	if e != nil {
+		panic(e)
+	}
+}
+
+// Write the ORF coordinates in BED format (BED6)
+// NOTE: ORFs spanning the origin of circular sequences are split in two
+// lines with the same name
+func writeBed(w io.Writer, s seq.Seq, orfs []orf.ORF) {
+	for i, f := range orfs {
+		for _, p := range f.Split(s.Length()) {
+			fmt.Fprintf(w, "%s\t%d\t%d\t%s_orf%d\t0\t%c\n", s.Id, p.Start, p.End, s.Id, i+1, p.Strand)
+		}
+	}
+}
+
+// Write the ORF coordinates in GFF3 format
+// NOTE: ORFs spanning the origin of circular sequences are written as
+// discontinuous features (two lines with the same ID), the phase of each
+// part is given in the translation order
+func writeGff(w io.Writer, s seq.Seq, orfs []orf.ORF) {
+	for i, f := range orfs {
+		partial := ""
+		if f.Partial {
+			partial = ";partial=true"
+		}
+		parts := f.Split(s.Length())
+		phases := make([]int, len(parts))
+		if len(parts) == 2 {
+			// The second part in the translation order starts after the
+			// first one
+			if f.Strand == '-' {
+				phases[0] = (3 - parts[1].Length()%3) % 3
+			} else {
+				phases[1] = (3 - parts[0].Length()%3) % 3
+			}
+		}
+		for j, p := range parts {
+			fmt.Fprintf(w, "%s\tgo-seq\tORF\t%d\t%d\t.\t%c\t%d\tID=%s_orf%d;frame=%+d%s\n", s.Id, p.Start+1, p.End, p.Strand, phases[j], s.Id, i+1, p.Frame, partial)
+		}
+	}
+}
+
+func main() {
+	// Retrieve argument values
+	input := flag.String("input", "STDIN", "Input sequence file.")
+	format := flag.String("format", "auto", "Input format (auto: detected from the input).")
+	output := flag.String("output", "", "Output file (default: stdout).")
+	outType := flag.String("type", "bed", "Output type: bed, gff, nucl (ORF sequences) or prot (ORF proteins).")
+	outFormat := flag.String("out-format", "fasta", "Output sequence format (nucl and prot types).")
+	table := flag.Int("table", 1, "NCBI translation table.")
+	min := flag.Int("min", 100, "Minimum ORF length (amino acids).")
+	starts := flag.String("start", "atg", "Start codons: atg, alt (alternative starts of the table) or any (stop to stop).")
+	circular := flag.Bool("circular", false, "Circular input sequences.")
+	partial := flag.Bool("partial", false, "Report the ORFs without stop codon at the end of the sequences.")
+	flag.Parse()
+
+	if *input == "" {
+		panic("You must provide an input sequence file.")
+	}
+
+	// Set up the ORF finder
+	t, err := translate.GetTable(*table)
+	check(err)
+	opt := orf.Options{
+		Table:     t,
+		MinLength: *min,
+		Circular:  *circular,
+		Partial:   *partial,
+	}
+	switch *starts {
+	case "atg":
+		opt.Starts = orf.StartATG
+	case "alt":
+		opt.Starts = orf.StartTable
+	case "any":
+		opt.Starts = orf.StartAny
+	default:
+		panic("Unknown start codon set: " + *starts)
+	}
+
+	seqIn, err := seqio.Open(*input, *format, seqio.WithAlphabet(alphabet.IUPAC))
+	check(err)
+	defer seqIn.Close()
+
+	// Set up the output
+	var seqOut *seqio.Writer
+	var out *bufio.Writer
+	switch *outType {
+	case "bed", "gff":
+		f := os.Stdout
+		if *output != "" {
+			f, err = os.Create(*output)
+			check(err)
+			defer f.Close()
+		}
+		out = bufio.NewWriter(f)
+		defer out.Flush()
+		if *outType == "gff" {
+			fmt.Fprintln(out, "##gff-version 3")
+		}
+	case "nucl", "prot":
+		seqOut, err = seqio.Create(*output, *outFormat)
+		check(err)
+		defer seqOut.Close()
+	default:
+		panic("Unknown output type: " + *outType)
+	}
+
+	for seqIn.Next() {
+		s := seqIn.Seq()
+		orfs := orf.Find(s, opt)
+
+		switch *outType {
+		case "bed":
+			writeBed(out, s, orfs)
+		case "gff":
+			writeGff(out, s, orfs)
+		default:
+			for i, f := range orfs {
+				o := seq.Seq{
+					Id:   fmt.Sprintf("%s_orf%d", s.Id, i+1),
+					Desc: fmt.Sprintf("%s frame=%+d", f.Region(s.Id), f.Frame),
+				}
+				if *outType == "nucl" {
+					o.Sequence = f.Nucleotides(s)
+				} else {
+					o.Sequence = f.Protein(s, t)
+				}
+				check(seqOut.Write(o))
+			}
+		}
+	}
+	check(seqIn.Err())
+}
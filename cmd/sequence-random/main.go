@@ -48,7 +48,12 @@ func main() {
 	os.Stderr.WriteString(fmt.Sprintf("Used random seed: %d\n", *seed))
 
 	// Open ouput file
-	seqOut := seqio.NewWriter(*output, *format, *gzip)
+	// NOTE: without -c, the compression is selected from the file extension
+	compress := seqio.AutoCompression
+	if *gzip {
+		compress = seqio.Gzip
+	}
+	seqOut := seqio.NewCompressedWriter(*output, *format, compress)
 	seqOut.CheckPanic()
 	defer seqOut.Close()
 
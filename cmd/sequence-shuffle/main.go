@@ -18,10 +18,9 @@ func check(e error) {
 func main() {
 	// Retrieve argument values
 	input := flag.String("input", "STDIN", "Input sequence file.")
-	format := flag.String("format", "fasta", "Input/output format.")
+	format := flag.String("format", "auto", "Input/output format (auto: detected from the input).")
 	output := flag.String("output", "", "Output sequence file.")
 	gzip := flag.Bool("c", false, "Compress the output (gz).")
-	gunzip := flag.Bool("d", false, "Decompress the input (gz).")
 	flag.Parse()
 
 	if *input == "" {
@@ -32,9 +31,9 @@ func main() {
 	seeder := rand.NewSource(time.Now().UnixNano())
 	random := rand.New(seeder)
 
-	// Read input sequences
+	// Read input sequences (the compression is detected from the input)
 	var seqs []seq.Seq
-	seqIn := seqio.NewReader(*input, *format, *gunzip)
+	seqIn := seqio.NewCompressedReader(*input, *format, seqio.AutoCompression)
 	seqIn.CheckPanic()
 	defer seqIn.Close()
 	for seqIn.Next() {
@@ -47,8 +46,16 @@ func main() {
 		seqs[i], seqs[j] = seqs[j], seqs[i]
 	})
 
-	// Save shuffled sequences in output
-	seqOut := seqio.NewWriter(*output, *format, *gzip)
+	// Save shuffled sequences in output (in the detected input format if required)
+	if *format == "auto" {
+		*format = seqIn.Format()
+	}
+	// NOTE: without -c, the compression is selected from the file extension
+	compress := seqio.AutoCompression
+	if *gzip {
+		compress = seqio.Gzip
+	}
+	seqOut := seqio.NewCompressedWriter(*output, *format, compress)
 	seqOut.CheckPanic()
 	defer seqOut.Close()
 
@@ -0,0 +1,52 @@
+package feature
+
+/*
+	Feature table entries shared by the annotated flat-file formats
+	(GenBank, EMBL). The location is kept as written in the file
+	(e.g. "complement(join(12..78,134..202))").
+*/
+
+// A single qualifier (/key=value) attached to a feature
+type Qualifier struct {
+	Key   string
+	Value string
+}
+
+// A feature table entry
+type Feature struct {
+	Key        string
+	Location   string
+	Qualifiers []Qualifier
+}
+
+// Feature builder
+func NewFeature(key, location string) *Feature {
+	f := Feature{Key: key, Location: location}
+	return &f
+}
+
+// Append a qualifier to the feature
+func (f *Feature) AddQualifier(key, value string) {
+	f.Qualifiers = append(f.Qualifiers, Qualifier{Key: key, Value: value})
+}
+
+// Get the value of the first qualifier with the given key
+func (f *Feature) Value(key string) (string, bool) {
+	for _, q := range f.Qualifiers {
+		if q.Key == key {
+			return q.Value, true
+		}
+	}
+	return "", false
+}
+
+// Get the values of all the qualifiers with the given key
+func (f *Feature) Values(key string) []string {
+	var values []string
+	for _, q := range f.Qualifiers {
+		if q.Key == key {
+			values = append(values, q.Value)
+		}
+	}
+	return values
+}
@@ -0,0 +1,211 @@
+package feature
+
+import (
+	"errors"
+	"strings"
+)
+
+/*
+	GenBank and EMBL share the same feature table layout once the line
+	prefix is removed ("     " in GenBank, "FT   " in EMBL):
+	- the feature key starts at column 0 and the location at column 16,
+	- qualifiers and location continuations start at column 16.
+*/
+
+const (
+	keyWidth  int = 16
+	lineWidth int = 79
+)
+
+// Qualifiers written without quotes
+var unquoted = map[string]bool{
+	"anticodon":        true,
+	"citation":         true,
+	"codon_start":      true,
+	"compare":          true,
+	"direction":        true,
+	"estimated_length": true,
+	"mod_base":         true,
+	"number":           true,
+	"rpt_type":         true,
+	"rpt_unit_range":   true,
+	"tag_peptide":      true,
+	"transl_except":    true,
+	"transl_table":     true,
+}
+
+// Feature table parser (fed line by line)
+type TableParser struct {
+	features []Feature
+	open     bool
+	inLoc    bool
+}
+
+// Generate a new feature table parser
+func NewTableParser() *TableParser {
+	return &TableParser{}
+}
+
+// Parse a feature table line (without its prefix)
+func (t *TableParser) ParseLine(line string) error {
+	if strings.TrimSpace(line) == "" {
+		return nil
+	}
+
+	// New feature
+	if line[0] != ' ' {
+		if t.open {
+			return errors.New("Unterminated qualifier value")
+		}
+		if len(line) <= keyWidth {
+			return errors.New("Feature without location (" + strings.TrimSpace(line) + ")")
+		}
+		key := strings.TrimSpace(line[:keyWidth])
+		loc := strings.TrimSpace(line[keyWidth:])
+		t.features = append(t.features, *NewFeature(key, loc))
+		t.inLoc = true
+		return nil
+	}
+
+	if len(t.features) == 0 {
+		return errors.New("Qualifier without feature")
+	}
+	f := &t.features[len(t.features)-1]
+	data := strings.TrimSpace(line)
+
+	// Continuation of a multi-line qualifier value
+	if t.open {
+		q := &f.Qualifiers[len(f.Qualifiers)-1]
+		if q.Key == "translation" {
+			q.Value += data
+		} else {
+			q.Value += " " + data
+		}
+		t.open = isOpenQuote(q.Value)
+		if !t.open {
+			q.Value = unquote(q.Value)
+		}
+		return nil
+	}
+
+	// New qualifier
+	if data[0] == '/' {
+		t.inLoc = false
+		kv := strings.SplitN(data[1:], "=", 2)
+		if len(kv) == 1 {
+			f.AddQualifier(kv[0], "")
+			return nil
+		}
+		f.AddQualifier(kv[0], kv[1])
+		q := &f.Qualifiers[len(f.Qualifiers)-1]
+		t.open = isOpenQuote(q.Value)
+		if !t.open {
+			q.Value = unquote(q.Value)
+		}
+		return nil
+	}
+
+	// Continuation of a multi-line location
+	if t.inLoc {
+		f.Location += data
+		return nil
+	}
+
+	return errors.New("Unexpected line (" + data + ")")
+}
+
+// Return the parsed features and reset the parser
+func (t *TableParser) Features() ([]Feature, error) {
+	if t.open {
+		return nil, errors.New("Unterminated qualifier value")
+	}
+	features := t.features
+	t.features = nil
+	t.inLoc = false
+	return features, nil
+}
+
+// A quoted value is open while it does not end with a closing quote
+func isOpenQuote(v string) bool {
+	if len(v) == 0 || v[0] != '"' {
+		return false
+	}
+	return strings.Count(v, "\"")%2 == 1
+}
+
+func unquote(v string) string {
+	if len(v) >= 2 && v[0] == '"' && v[len(v)-1] == '"' {
+		return strings.Replace(v[1:len(v)-1], "\"\"", "\"", -1)
+	}
+	return v
+}
+
+// Format a feature as table lines, each line starts with the given prefix
+func (f *Feature) Lines(prefix string) []string {
+	var lines []string
+	indent := prefix + strings.Repeat(" ", keyWidth)
+	width := lineWidth - len(indent)
+
+	// Feature key and location (location is split after commas)
+	loc := wrap(f.Location, width, ",")
+	lines = append(lines, prefix+padRight(f.Key, keyWidth)+loc[0])
+	for _, l := range loc[1:] {
+		lines = append(lines, indent+l)
+	}
+
+	// Qualifiers
+	for _, q := range f.Qualifiers {
+		var text string
+		if q.Value == "" {
+			text = "/" + q.Key
+		} else if unquoted[q.Key] {
+			text = "/" + q.Key + "=" + q.Value
+		} else {
+			text = "/" + q.Key + "=\"" + strings.Replace(q.Value, "\"", "\"\"", -1) + "\""
+		}
+		sep := " "
+		if q.Key == "translation" {
+			sep = ""
+		}
+		for _, l := range wrap(text, width, sep) {
+			lines = append(lines, indent+l)
+		}
+	}
+
+	return lines
+}
+
+// Split a text in chunks no longer than width, preferably after sep
+// NOTE: words are never split with the " " separator (the lines are joined
+// with a space when read back), a chunk can then be longer than width
+func wrap(text string, width int, sep string) []string {
+	var chunks []string
+	for len(text) > width {
+		cut := width
+		if sep != "" {
+			if i := strings.LastIndex(text[:width], sep); i > 0 {
+				cut = i + len(sep)
+			} else if sep == " " {
+				i = strings.Index(text[width:], sep)
+				if i < 0 {
+					break
+				}
+				cut = width + i + len(sep)
+			}
+		}
+		chunk := text[:cut]
+		if sep == " " {
+			chunk = strings.TrimRight(chunk, " ")
+		}
+		chunks = append(chunks, chunk)
+		text = text[cut:]
+	}
+	return append(chunks, text)
+}
+
+func padRight(s string, n int) string {
+	if len(s) >= n {
+		return s + " "
+	}
+	return s + strings.Repeat(" ", n-len(s))
+}
@@ -0,0 +1,79 @@
+package feature
+
+import (
+	"strings"
+	"testing"
+)
+
+// Parse the lines of a feature table
+func parseLines(t *testing.T, lines []string) []Feature {
+	t.Helper()
+	p := NewTableParser()
+	for _, l := range lines {
+		if err := p.ParseLine(l); err != nil {
+			t.Fatalf("ParseLine(%q): %v", l, err)
+		}
+	}
+	features, err := p.Features()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return features
+}
+
+func TestWrap(t *testing.T) {
+	long := strings.Repeat("x", 30)
+	tests := []struct {
+		text, sep string
+		chunks    []string
+	}{
+		{"short", " ", []string{"short"}},
+		{"aaaa bbbb cccc", " ", []string{"aaaa bbbb", "cccc"}},
+		{long + " end", " ", []string{long, "end"}},
+		{"a " + long, " ", []string{"a", long}},
+		{long, "", []string{long[:10], long[10:20], long[20:]}},
+		{"1..10,20..30,40..50", ",", []string{"1..10,", "20..30,", "40..50"}},
+	}
+	for _, tt := range tests {
+		chunks := wrap(tt.text, 10, tt.sep)
+		if strings.Join(chunks, "|") != strings.Join(tt.chunks, "|") {
+			t.Errorf("wrap(%q, %q) = %q, want %q", tt.text, tt.sep, chunks, tt.chunks)
+		}
+	}
+}
+
+func TestLinesRoundTrip(t *testing.T) {
+	f := NewFeature("CDS", "join("+strings.Repeat("1000..2000,", 10)+"3000..4000)")
+	f.AddQualifier("note", "see https://www.ncbi.nlm.nih.gov/nuccore/NC_001422.1?report=genbank&log$=seqview&format=text for the whole record")
+	f.AddQualifier("inference", "similar to "+strings.Repeat("ACGT", 30))
+	f.AddQualifier("product", "protein \"A\" with quotes")
+	f.AddQualifier("codon_start", "1")
+	f.AddQualifier("pseudo", "")
+	f.AddQualifier("translation", strings.Repeat("MKVLAAGIVGLLLA", 12))
+
+	for _, prefix := range []string{"     ", "FT   "} {
+		lines := f.Lines(prefix)
+		for i, l := range lines {
+			if !strings.HasPrefix(l, prefix) {
+				t.Fatalf("line %d without prefix: %q", i, l)
+			}
+			lines[i] = l[len(prefix):]
+		}
+		features := parseLines(t, lines)
+		if len(features) != 1 {
+			t.Fatalf("%d features", len(features))
+		}
+		g := features[0]
+		if g.Key != f.Key || g.Location != f.Location {
+			t.Errorf("%s %s, want %s %s", g.Key, g.Location, f.Key, f.Location)
+		}
+		if len(g.Qualifiers) != len(f.Qualifiers) {
+			t.Fatalf("%d qualifiers, want %d", len(g.Qualifiers), len(f.Qualifiers))
+		}
+		for i, q := range f.Qualifiers {
+			if g.Qualifiers[i] != q {
+				t.Errorf("qualifier %q, want %q", g.Qualifiers[i], q)
+			}
+		}
+	}
+}
@@ -3,6 +3,7 @@ module github.com/hdevillers/go-seq
 go 1.15
 
 require (
-	github.com/klauspost/compress v1.12.3 // indirect
+	github.com/klauspost/compress v1.12.3
 	github.com/klauspost/pgzip v1.2.5
+	github.com/ulikunitz/xz v0.5.11
 )
@@ -0,0 +1,52 @@
+package header
+
+import (
+	"github.com/hdevillers/go-seq/seq"
+)
+
+/*
+	Structured parsing of the sequencing read headers (ID and description
+	of the sequences).
+*/
+
+// Sequencing platforms
+type Platform int
+
+const (
+	Unknown Platform = iota
+	Illumina
+	Nanopore
+	PacBio
+)
+
+// Platform names
+func (p Platform) String() string {
+	switch p {
+	case Illumina:
+		return "illumina"
+	case Nanopore:
+		return "nanopore"
+	case PacBio:
+		return "pacbio"
+	}
+	return "unknown"
+}
+
+// Identify the platform from a read ID and its description
+func Detect(id, desc string) Platform {
+	if _, err := ParseIllumina(id, desc); err == nil {
+		return Illumina
+	}
+	if _, err := ParsePacBio(id); err == nil {
+		return PacBio
+	}
+	if _, err := ParseNanopore(id, desc); err == nil {
+		return Nanopore
+	}
+	return Unknown
+}
+
+// Identify the platform of a sequence
+func DetectSeq(s seq.Seq) Platform {
+	return Detect(s.Id, s.Desc)
+}
@@ -0,0 +1,152 @@
+package header
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseIllumina(t *testing.T) {
+	tests := []struct {
+		id, desc string
+		h        IlluminaHeader
+	}{
+		// Casava 1.8+
+		{"EAS139:136:FC706VJ:2:2104:15343:197393", "1:Y:18:ATCACG",
+			IlluminaHeader{Instrument: "EAS139", Run: 136, Flowcell: "FC706VJ", Lane: 2, Tile: 2104, X: 15343, Y: 197393, Read: 1, Filtered: true, Control: 18, Index: "ATCACG"}},
+		{"A00123:8:H5KJ2DSXX:1:1101:1000:1000", "2:N:0:ACGTACGT+TTAGGCAT extra",
+			IlluminaHeader{Instrument: "A00123", Run: 8, Flowcell: "H5KJ2DSXX", Lane: 1, Tile: 1101, X: 1000, Y: 1000, Read: 2, Index: "ACGTACGT+TTAGGCAT"}},
+		{"NB551068:9:HFVMLBGX2:1:11101:10237:1053:GATCTGTG", "1:N:0:2",
+			IlluminaHeader{Instrument: "NB551068", Run: 9, Flowcell: "HFVMLBGX2", Lane: 1, Tile: 11101, X: 10237, Y: 1053, UMI: "GATCTGTG", Read: 1, Index: "2"}},
+		{"EAS139:136:FC706VJ:2:2104:15343:197393", "",
+			IlluminaHeader{Instrument: "EAS139", Run: 136, Flowcell: "FC706VJ", Lane: 2, Tile: 2104, X: 15343, Y: 197393}},
+
+		// Older pipelines
+		{"HWUSI-EAS100R:6:73:941:1973#0/1", "",
+			IlluminaHeader{Instrument: "HWUSI-EAS100R", Lane: 6, Tile: 73, X: 941, Y: 1973, Read: 1, Index: "0"}},
+		{"HWI-ST361:8:1101:1214:2245#ACGTAC/2", "",
+			IlluminaHeader{Instrument: "HWI-ST361", Lane: 8, Tile: 1101, X: 1214, Y: 2245, Read: 2, Index: "ACGTAC"}},
+		{"HWI-ST361:8:1101:1214:2245", "",
+			IlluminaHeader{Instrument: "HWI-ST361", Lane: 8, Tile: 1101, X: 1214, Y: 2245}},
+	}
+	for _, tt := range tests {
+		h, err := ParseIllumina(tt.id, tt.desc)
+		if err != nil || !reflect.DeepEqual(*h, tt.h) {
+			t.Errorf("ParseIllumina(%s, %s) = %+v, %v, want %+v", tt.id, tt.desc, h, err, tt.h)
+		}
+	}
+
+	bad := []struct{ id, desc string }{
+		{"read1", ""},
+		{"A:B:C", ""},
+		{"EAS139:x:FC706VJ:2:2104:15343:197393", ""},
+		{"EAS139:136:FC706VJ:2:2104:15343:y", ""},
+		{"EAS139:136:FC706VJ:2:2104:15343:197393", "1:X:18:ATCACG"},
+		{"EAS139:136:FC706VJ:2:2104:15343:197393", "1:Y:18"},
+		{"EAS139:136:FC706VJ:2:2104:15343:197393", "x:Y:18:ATCACG"},
+		{"EAS139:136:FC706VJ:2:2104:15343:197393", "1:N:x:ATCACG"},
+		{"HWUSI-EAS100R:6:73:941:1973#0/x", ""},
+		{"HWUSI-EAS100R:6:73:941:y#0/1", ""},
+		{"HWUSI-EAS100R:lane:73:941:1973", ""},
+	}
+	for _, tt := range bad {
+		if _, err := ParseIllumina(tt.id, tt.desc); err == nil {
+			t.Errorf("ParseIllumina(%s, %s): no error", tt.id, tt.desc)
+		}
+	}
+}
+
+func TestParseNanopore(t *testing.T) {
+	id := "0a1b2c3d-4e5f-6789-abcd-ef0123456789"
+	desc := "runid=f2b5c8d1e0a9 sampleid=sample1 read=12 ch=301 start_time=2021-03-04T10:11:12Z flow_cell_id=FAP12345 protocol_group_id=exp1 barcode=barcode01"
+	h, err := ParseNanopore(id, desc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := NanoporeHeader{
+		ReadId:     id,
+		RunId:      "f2b5c8d1e0a9",
+		SampleId:   "sample1",
+		FlowcellId: "FAP12345",
+		Barcode:    "barcode01",
+		Channel:    301,
+		Read:       12,
+		StartTime:  time.Date(2021, 3, 4, 10, 11, 12, 0, time.UTC),
+		Fields:     h.Fields,
+	}
+	if !reflect.DeepEqual(*h, want) || len(h.Fields) != 8 || h.Fields["protocol_group_id"] != "exp1" {
+		t.Errorf("ParseNanopore = %+v", h)
+	}
+
+	// Only the run ID is required
+	h, err = ParseNanopore(id, "runid=abc")
+	if err != nil || h.RunId != "abc" || h.Channel != 0 || !h.StartTime.IsZero() {
+		t.Errorf("ParseNanopore(runid=abc) = %+v, %v", h, err)
+	}
+
+	for _, desc := range []string{
+		"",
+		"read=12 ch=301",
+		"runid=abc ch=x",
+		"runid=abc read=1.5",
+		"runid=abc start_time=2021-03-04",
+		"runid=abc free text",
+		"runid=abc =x",
+	} {
+		if _, err := ParseNanopore(id, desc); err == nil {
+			t.Errorf("ParseNanopore(%s): no error", desc)
+		}
+	}
+}
+
+func TestParsePacBio(t *testing.T) {
+	tests := []struct {
+		id     string
+		h      PacBioHeader
+		length int
+	}{
+		{"m54006_160504_020705/4194370/0_1823", PacBioHeader{Movie: "m54006_160504_020705", ZMW: 4194370, Start: 0, End: 1823}, 1823},
+		{"m64011_190830_220126/1/ccs", PacBioHeader{Movie: "m64011_190830_220126", ZMW: 1, Start: -1, End: -1, CCS: true}, -1},
+		{"m130802_221257_42156_c100562662550000001823090912221380_s1_p0/8/1000_2500", PacBioHeader{Movie: "m130802_221257_42156_c100562662550000001823090912221380_s1_p0", ZMW: 8, Start: 1000, End: 2500}, 1500},
+		{"m54006_160504_020705/4194370", PacBioHeader{Movie: "m54006_160504_020705", ZMW: 4194370, Start: -1, End: -1}, -1},
+	}
+	for _, tt := range tests {
+		h, err := ParsePacBio(tt.id)
+		if err != nil || *h != tt.h || h.Length() != tt.length {
+			t.Errorf("ParsePacBio(%s) = %+v, %v, want %+v", tt.id, h, err, tt.h)
+		}
+	}
+	for _, id := range []string{
+		"read1",
+		"m54006/x/0_10",
+		"m54006/1/0-10",
+		"m54006/1/a_10",
+		"m54006/1/0_b",
+		"m54006/1/0_10/1",
+		"x54006/1/0_10",
+	} {
+		if _, err := ParsePacBio(id); err == nil {
+			t.Errorf("ParsePacBio(%s): no error", id)
+		}
+	}
+}
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		id, desc string
+		p        Platform
+	}{
+		{"EAS139:136:FC706VJ:2:2104:15343:197393", "1:Y:18:ATCACG", Illumina},
+		{"HWUSI-EAS100R:6:73:941:1973#0/1", "", Illumina},
+		{"m54006_160504_020705/4194370/0_1823", "", PacBio},
+		{"0a1b2c3d-4e5f-6789-abcd-ef0123456789", "runid=abc read=12 ch=301", Nanopore},
+		{"read1", "", Unknown},
+		{"read1", "free text", Unknown},
+		{"EAS139:136:FC706VJ:2:2104:15343:197393", "1:X:18:ATCACG", Unknown},
+	}
+	for _, tt := range tests {
+		if p := Detect(tt.id, tt.desc); p != tt.p {
+			t.Errorf("Detect(%s, %s) = %s, want %s", tt.id, tt.desc, p, tt.p)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package header
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hdevillers/go-seq/seq"
+)
+
+// Illumina read header
+// NOTE: Casava 1.8+ headers look like
+// "INSTR:RUN:FLOWCELL:LANE:TILE:X:Y[:UMI] READ:FILTERED:CONTROL:INDEX" and
+// older ones like "INSTR:LANE:TILE:X:Y#INDEX/READ" (Run and Flowcell are
+// not set)
+type IlluminaHeader struct {
+	Instrument string
+	Run        int
+	Flowcell   string
+	Lane       int
+	Tile       int
+	X          int
+	Y          int
+	UMI        string
+	Read       int
+	Filtered   bool
+	Control    int
+	Index      string
+}
+
+// Parse an Illumina read header
+func ParseIllumina(id, desc string) (*IlluminaHeader, error) {
+	fields := strings.Split(id, ":")
+	switch len(fields) {
+	case 7, 8:
+		return parseCasava(id, fields, desc)
+	case 5:
+		return parseOldIllumina(id, fields)
+	}
+	return nil, fmt.Errorf("[ILLUMINA HEADER]: Unexpected ID format (%s).", id)
+}
+
+// Parse the Illumina header of a sequence
+func ParseIlluminaSeq(s seq.Seq) (*IlluminaHeader, error) {
+	return ParseIllumina(s.Id, s.Desc)
+}
+
+// Parse integer fields
+func atoiFields(id string, fields []string, values ...*int) error {
+	for i, v := range values {
+		n, err := strconv.Atoi(fields[i])
+		if err != nil {
+			return fmt.Errorf("[ILLUMINA HEADER]: Unexpected ID format (%s).", id)
+		}
+		*v = n
+	}
+	return nil
+}
+
+func parseCasava(id string, fields []string, desc string) (*IlluminaHeader, error) {
+	h := IlluminaHeader{
+		Instrument: fields[0],
+		Flowcell:   fields[2],
+	}
+	err := atoiFields(id, fields[1:2], &h.Run)
+	if err == nil {
+		err = atoiFields(id, fields[3:7], &h.Lane, &h.Tile, &h.X, &h.Y)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) == 8 {
+		h.UMI = fields[7]
+	}
+
+	// Read information (first word of the description)
+	if i := strings.IndexAny(desc, " \t"); i >= 0 {
+		desc = desc[:i]
+	}
+	if desc == "" {
+		return &h, nil
+	}
+	info := strings.Split(desc, ":")
+	if len(info) != 4 || (info[1] != "Y" && info[1] != "N") {
+		return nil, fmt.Errorf("[ILLUMINA HEADER]: Unexpected read information (%s).", desc)
+	}
+	h.Filtered = info[1] == "Y"
+	h.Index = info[3]
+	err = atoiFields(desc, []string{info[0], info[2]}, &h.Read, &h.Control)
+	if err != nil {
+		return nil, fmt.Errorf("[ILLUMINA HEADER]: Unexpected read information (%s).", desc)
+	}
+	return &h, nil
+}
+
+func parseOldIllumina(id string, fields []string) (*IlluminaHeader, error) {
+	h := IlluminaHeader{
+		Instrument: fields[0],
+	}
+
+	// The last field may end with #INDEX and /READ
+	last := fields[4]
+	if i := strings.LastIndexByte(last, '/'); i >= 0 {
+		n, err := strconv.Atoi(last[i+1:])
+		if err != nil {
+			return nil, fmt.Errorf("[ILLUMINA HEADER]: Unexpected ID format (%s).", id)
+		}
+		h.Read = n
+		last = last[:i]
+	}
+	if i := strings.IndexByte(last, '#'); i >= 0 {
+		h.Index = last[i+1:]
+		last = last[:i]
+	}
+	err := atoiFields(id, []string{fields[1], fields[2], fields[3], last}, &h.Lane, &h.Tile, &h.X, &h.Y)
+	if err != nil {
+		return nil, err
+	}
+	return &h, nil
+}
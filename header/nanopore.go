@@ -0,0 +1,73 @@
+package header
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hdevillers/go-seq/seq"
+)
+
+// Oxford Nanopore read header
+// NOTE: the description is made of key=value fields (e.g., "runid=...
+// read=12 ch=301 start_time=2021-03-04T10:11:12Z"), all of them are kept
+// in Fields
+type NanoporeHeader struct {
+	ReadId     string
+	RunId      string
+	SampleId   string
+	FlowcellId string
+	Barcode    string
+	Channel    int
+	Read       int
+	StartTime  time.Time
+	Fields     map[string]string
+}
+
+// Parse a Nanopore read header
+func ParseNanopore(id, desc string) (*NanoporeHeader, error) {
+	h := NanoporeHeader{
+		ReadId: id,
+		Fields: make(map[string]string),
+	}
+	for _, kv := range strings.Fields(desc) {
+		i := strings.IndexByte(kv, '=')
+		if i <= 0 {
+			return nil, fmt.Errorf("[NANOPORE HEADER]: Unexpected field (%s).", kv)
+		}
+		h.Fields[kv[:i]] = kv[i+1:]
+	}
+
+	// Nanopore reads have at least a run ID
+	var ok bool
+	if h.RunId, ok = h.Fields["runid"]; !ok {
+		return nil, fmt.Errorf("[NANOPORE HEADER]: Missing runid field (%s).", id)
+	}
+	h.SampleId = h.Fields["sampleid"]
+	h.FlowcellId = h.Fields["flow_cell_id"]
+	h.Barcode = h.Fields["barcode"]
+
+	var err error
+	if v, ok := h.Fields["ch"]; ok {
+		if h.Channel, err = strconv.Atoi(v); err != nil {
+			return nil, fmt.Errorf("[NANOPORE HEADER]: Bad channel value (%s).", v)
+		}
+	}
+	if v, ok := h.Fields["read"]; ok {
+		if h.Read, err = strconv.Atoi(v); err != nil {
+			return nil, fmt.Errorf("[NANOPORE HEADER]: Bad read value (%s).", v)
+		}
+	}
+	if v, ok := h.Fields["start_time"]; ok {
+		if h.StartTime, err = time.Parse(time.RFC3339, v); err != nil {
+			return nil, fmt.Errorf("[NANOPORE HEADER]: Bad start_time value (%s).", v)
+		}
+	}
+	return &h, nil
+}
+
+// Parse the Nanopore header of a sequence
+func ParseNanoporeSeq(s seq.Seq) (*NanoporeHeader, error) {
+	return ParseNanopore(s.Id, s.Desc)
+}
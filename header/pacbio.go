@@ -0,0 +1,69 @@
+package header
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hdevillers/go-seq/seq"
+)
+
+// PacBio read header
+// NOTE: IDs look like "MOVIE/ZMW/START_END" (subreads), "MOVIE/ZMW/ccs"
+// (circular consensus) or "MOVIE/ZMW", Start and End are -1 if the read
+// coordinates are not given
+type PacBioHeader struct {
+	Movie string
+	ZMW   int
+	Start int
+	End   int
+	CCS   bool
+}
+
+// Parse a PacBio read header
+func ParsePacBio(id string) (*PacBioHeader, error) {
+	fields := strings.Split(id, "/")
+	if len(fields) < 2 || len(fields) > 3 || !strings.HasPrefix(fields[0], "m") {
+		return nil, fmt.Errorf("[PACBIO HEADER]: Unexpected ID format (%s).", id)
+	}
+	h := PacBioHeader{
+		Movie: fields[0],
+		Start: -1,
+		End:   -1,
+	}
+	var err error
+	if h.ZMW, err = strconv.Atoi(fields[1]); err != nil {
+		return nil, fmt.Errorf("[PACBIO HEADER]: Bad ZMW number (%s).", id)
+	}
+	if len(fields) == 3 {
+		if fields[2] == "ccs" {
+			h.CCS = true
+			return &h, nil
+		}
+		coord := strings.Split(fields[2], "_")
+		if len(coord) != 2 {
+			return nil, fmt.Errorf("[PACBIO HEADER]: Bad read coordinates (%s).", id)
+		}
+		h.Start, err = strconv.Atoi(coord[0])
+		if err == nil {
+			h.End, err = strconv.Atoi(coord[1])
+		}
+		if err != nil {
+			return nil, fmt.Errorf("[PACBIO HEADER]: Bad read coordinates (%s).", id)
+		}
+	}
+	return &h, nil
+}
+
+// Parse the PacBio header of a sequence
+func ParsePacBioSeq(s seq.Seq) (*PacBioHeader, error) {
+	return ParsePacBio(s.Id)
+}
+
+// Length of the subread (-1 if the coordinates are not given)
+func (h *PacBioHeader) Length() int {
+	if h.Start < 0 {
+		return -1
+	}
+	return h.End - h.Start
+}
@@ -0,0 +1,216 @@
+package orf
+
+import (
+	"sort"
+
+	"github.com/hdevillers/go-seq/seq"
+	"github.com/hdevillers/go-seq/translate"
+)
+
+// Start codon sets
+type StartMode int
+
+const (
+	// ATG only
+	StartATG StartMode = iota
+	// Start codons of the translation table (alternative starts)
+	StartTable
+	// Any codon (stop to stop ORFs)
+	StartAny
+)
+
+// ORF finder options
+// NOTE: the zero value finds ORFs starting with ATG of at least one amino
+// acid with the standard code in linear sequences
+type Options struct {
+	// Translation table (nil: standard)
+	Table *translate.Table
+	// Minimum length in amino acids (stop codon excluded)
+	MinLength int
+	// Start codon set
+	Starts StartMode
+	// Circular sequence (ORFs can span the origin)
+	Circular bool
+	// Report the ORFs without stop codon at the end of linear sequences
+	Partial bool
+}
+
+// Open reading frame
+// NOTE: coordinates are 0-based half-open on the forward strand, the stop
+// codon is included; End is larger than the sequence length if the ORF
+// spans the origin of a circular sequence
+type ORF struct {
+	Start   int
+	End     int
+	Strand  byte
+	Frame   int
+	Partial bool
+}
+
+// ORF length in nucleotides
+func (f ORF) Length() int {
+	return f.End - f.Start
+}
+
+// Split an ORF spanning the origin of a circular sequence of length n in
+// two parts ([Start, n) and [0, End-n)), other ORFs are returned as is
+func (f ORF) Split(n int) []ORF {
+	if f.End <= n {
+		return []ORF{f}
+	}
+	f1, f2 := f, f
+	f1.End = n
+	f2.Start = 0
+	f2.End = f.End - n
+	return []ORF{f1, f2}
+}
+
+// Get the region of the ORF (e.g., "chr1:100-300(-)")
+// NOTE: End is not wrapped for ORFs spanning the origin (see Split)
+func (f ORF) Region(id string) seq.Region {
+	return seq.NewRegion(id, f.Start, f.End, f.Strand)
+}
+
+// Get the nucleotides of the ORF (reverse-complemented on the - strand)
+func (f ORF) Nucleotides(s seq.Seq) []byte {
+	n := s.Length()
+	nt := make([]byte, 0, f.Length())
+	for i := f.Start; i < f.End; i++ {
+		nt = append(nt, s.Sequence[i%n])
+	}
+	if f.Strand == '-' {
+		seq.ReverseComplementBytes(nt)
+	}
+	return nt
+}
+
+// Get the protein of the ORF (without the stop codon)
+// NOTE: the first codon is translated as M if it is a start codon
+func (f ORF) Protein(s seq.Seq, t *translate.Table) []byte {
+	prot, _ := translate.TranslateBytes(f.Nucleotides(s), translate.Options{
+		Table:    t,
+		AltStart: true,
+		TrimStop: true,
+	})
+	return prot
+}
+
+// Check if a codon is ATG (or AUG)
+func isATG(c []byte) bool {
+	return c[0]|0x20 == 'a' && (c[1]|0x20 == 't' || c[1]|0x20 == 'u') && c[2]|0x20 == 'g'
+}
+
+// Check if a codon can start an ORF
+func (o *Options) isStart(t *translate.Table, c []byte) bool {
+	switch o.Starts {
+	case StartTable:
+		return t.IsStart(c)
+	case StartAny:
+		return true
+	}
+	return isATG(c)
+}
+
+// Scan the three frames of a strand
+// NOTE: b is the sequence of the strand, doubled for circular sequences
+// (n is the length of the sequence)
+func (o *Options) scan(b []byte, n int, t *translate.Table) []ORF {
+	var orfs []ORF
+	min := 3 * o.MinLength
+	if min < 3 {
+		min = 3
+	}
+	for frame := 0; frame < 3; frame++ {
+		start := -1
+		i := frame
+		for ; i+3 <= len(b); i += 3 {
+			c := b[i : i+3]
+			if t.IsStop(c) {
+				// Only ORFs starting in the first copy of a circular
+				// sequence and shorter than the sequence are kept
+				if start >= 0 && start < n && i-start >= min && i+3-start <= n {
+					orfs = append(orfs, ORF{Start: start, End: i + 3, Frame: frame + 1})
+				}
+				start = -1
+				continue
+			}
+			if start < 0 && o.isStart(t, c) {
+				start = i
+			}
+		}
+		if o.Partial && !o.Circular && start >= 0 && i-start >= min {
+			orfs = append(orfs, ORF{Start: start, End: i, Frame: frame + 1, Partial: true})
+		}
+	}
+	return orfs
+}
+
+// Keep the longest ORF for each stop codon (circular sequences)
+func longestByStop(orfs []ORF, n int) []ORF {
+	best := make(map[int]int)
+	var kept []ORF
+	for _, f := range orfs {
+		stop := f.End % n
+		if k, ok := best[stop]; ok {
+			if f.Length() > kept[k].Length() {
+				kept[k] = f
+			}
+			continue
+		}
+		best[stop] = len(kept)
+		kept = append(kept, f)
+	}
+	return kept
+}
+
+// Find the ORFs of a nucleotide sequence on both strands
+func Find(s seq.Seq, o Options) []ORF {
+	t := o.Table
+	if t == nil {
+		t = translate.Standard
+	}
+	n := s.Length()
+	if n < 3 {
+		return nil
+	}
+
+	fwd := s.Sequence
+	if o.Circular {
+		fwd = append(append(make([]byte, 0, 2*n), s.Sequence...), s.Sequence...)
+	}
+	rev := make([]byte, n)
+	copy(rev, s.Sequence)
+	seq.ReverseComplementBytes(rev)
+	if o.Circular {
+		rev = append(rev, rev...)
+	}
+
+	plus := o.scan(fwd, n, t)
+	minus := o.scan(rev, n, t)
+	if o.Circular {
+		plus = longestByStop(plus, n)
+		minus = longestByStop(minus, n)
+	}
+
+	orfs := make([]ORF, 0, len(plus)+len(minus))
+	for _, f := range plus {
+		f.Strand = '+'
+		orfs = append(orfs, f)
+	}
+	for _, f := range minus {
+		// Convert to forward strand coordinates
+		l := f.Length()
+		f.Start = ((n-f.End)%n + n) % n
+		f.End = f.Start + l
+		f.Strand = '-'
+		f.Frame = -f.Frame
+		orfs = append(orfs, f)
+	}
+	sort.Slice(orfs, func(i, j int) bool {
+		if orfs[i].Start != orfs[j].Start {
+			return orfs[i].Start < orfs[j].Start
+		}
+		return orfs[i].Strand < orfs[j].Strand
+	})
+	return orfs
+}
@@ -0,0 +1,75 @@
+package orf
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hdevillers/go-seq/seq"
+)
+
+func TestFind(t *testing.T) {
+	// ATGAAATAA on the + strand (frame +3) and ATGCCCTAG on the - strand
+	// (frame -2)
+	linear := "CCATGAAATAAGGCTAGGGCATT"
+	plus := ORF{Start: 2, End: 11, Strand: '+', Frame: 3}
+	minus := ORF{Start: 13, End: 22, Strand: '-', Frame: -2}
+	tests := []struct {
+		name string
+		seq  string
+		o    Options
+		orfs []ORF
+	}{
+		{"linear", linear, Options{}, []ORF{plus, minus}},
+		{"minimum length", linear, Options{MinLength: 2}, []ORF{plus, minus}},
+		{"too short", linear, Options{MinLength: 3}, nil},
+		{"circular without spanning ORF", linear, Options{Circular: true}, []ORF{plus, minus}},
+		{"partial", "GGATGAAA", Options{Partial: true}, []ORF{{Start: 2, End: 8, Strand: '+', Frame: 3, Partial: true}}},
+		{"no partial", "GGATGAAA", Options{}, nil},
+		{"circular +", "GAAATAGCCCCCCCAT", Options{Circular: true}, []ORF{{Start: 14, End: 23, Strand: '+', Frame: 3}}},
+		{"circular -", "ATGGGGGGGCTATTTC", Options{Circular: true}, []ORF{{Start: 9, End: 18, Strand: '-', Frame: -3}}},
+		{"linear without stop", "GAAATAGCCCCCCCAT", Options{}, nil},
+		{"short sequence", "AT", Options{}, nil},
+	}
+	for _, tt := range tests {
+		orfs := Find(seq.Seq{Id: "s", Sequence: []byte(tt.seq)}, tt.o)
+		if len(orfs)+len(tt.orfs) > 0 && !reflect.DeepEqual(orfs, tt.orfs) {
+			t.Errorf("%s: %+v, want %+v", tt.name, orfs, tt.orfs)
+		}
+	}
+}
+
+func TestSequences(t *testing.T) {
+	tests := []struct {
+		seq, nucl, prot string
+		circular        bool
+	}{
+		{"CCATGAAATAAGGCTAGGGCATT", "ATGAAATAA", "MK", false},
+		{"GAAATAGCCCCCCCAT", "ATGAAATAG", "MK", true},
+		{"ATGGGGGGGCTATTTC", "ATGAAATAG", "MK", true},
+	}
+	for _, tt := range tests {
+		s := seq.Seq{Id: "s", Sequence: []byte(tt.seq)}
+		f := Find(s, Options{Circular: tt.circular})[0]
+		if nt := f.Nucleotides(s); string(nt) != tt.nucl {
+			t.Errorf("%s: Nucleotides = %s, want %s", tt.seq, nt, tt.nucl)
+		}
+		if p := f.Protein(s, nil); string(p) != tt.prot {
+			t.Errorf("%s: Protein = %s, want %s", tt.seq, p, tt.prot)
+		}
+	}
+}
+
+func TestSplit(t *testing.T) {
+	f := ORF{Start: 14, End: 23, Strand: '+', Frame: 3}
+	want := []ORF{{Start: 14, End: 16, Strand: '+', Frame: 3}, {Start: 0, End: 7, Strand: '+', Frame: 3}}
+	if parts := f.Split(16); !reflect.DeepEqual(parts, want) {
+		t.Errorf("Split = %+v, want %+v", parts, want)
+	}
+	f = ORF{Start: 2, End: 11, Strand: '-', Frame: -3}
+	if parts := f.Split(16); len(parts) != 1 || parts[0] != f {
+		t.Errorf("Split = %+v, want %+v", parts, f)
+	}
+	if r := f.Region("s").String(); r != "s:3-11(-)" {
+		t.Errorf("Region = %s", r)
+	}
+}
@@ -56,8 +56,19 @@ func (q *Quality) identifyPhredFromBytes(score []byte) error {
 		}
 	}
 
-	// NOTE: PHRED type not determined
-	return errors.New("[PHRED QUALITY]: Failed to determine the PHRED type.")
+	// NOTE: PHRED type not determined, the scores are valid with both types
+	// (e.g., only 'I' characters), the default type is used
+	return nil
+}
+
+// Check that the scores are consistent with the PHRED type
+func (q *Quality) checkPhredFromBytes(score []byte) error {
+	for _, b := range score {
+		if int(b) < q.Phred || b > '~' {
+			return errors.New("[PHRED QUALITY]: Unconsistant PHRED values.")
+		}
+	}
+	return nil
 }
 
 func (q *Quality) appendIntScoreFromByte(score []byte) {
@@ -74,9 +85,16 @@ func (q *Quality) appendIntScoreFromByte(score []byte) {
 func (q *Quality) AppendStrScore(score []byte) error {
 	var err error
 
-	// Uninitialized Phred type
+	// Nothing to append
+	if len(score) == 0 {
+		return nil
+	}
+
+	// Uninitialized Phred type (otherwise check the values)
 	if q.Phred == 0 {
 		err = q.identifyPhredFromBytes(score)
+	} else {
+		err = q.checkPhredFromBytes(score)
 	}
 
 	// Append the quality score (str)
@@ -95,4 +113,16 @@ func (q *Quality) GenerateDefaultScore(n int) {
 		q.StrScore = append(q.StrScore, defaultStrScore)
 		q.IntScore = append(q.IntScore, defaultIntScore)
 	}
-}
\ No newline at end of file
+}
+
+// Clear the scores but keep the allocated memory
+func (q *Quality) Reset() {
+	q.Phred = 0
+	q.IntScore = q.IntScore[:0]
+	q.StrScore = q.StrScore[:0]
+}
+
+// Number of scores
+func (q *Quality) Length() int {
+	return len(q.StrScore)
+}
@@ -0,0 +1,174 @@
+package seq
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Region errors
+var (
+	ErrInvalidRegion = errors.New("Invalid region")
+	ErrOutOfRange    = errors.New("Region out of range")
+)
+
+/*
+	NOTE: regions are stored with 0-based half-open coordinates (as Go
+	slices): the first base of a sequence is [0, 1). The 1-based closed
+	coordinates (as in GFF, SAM or samtools regions) are only used to build
+	or print regions (NewRegion1, ParseRegion and String).
+*/
+
+// Region of a sequence
+// NOTE: End is -1 for a region that goes up to the end of the sequence,
+// Strand is '+', '-' or 0 (unstranded)
+type Region struct {
+	Id     string
+	Start  int
+	End    int
+	Strand byte
+}
+
+// Create a region from 0-based half-open coordinates
+func NewRegion(id string, start, end int, strand byte) Region {
+	return Region{Id: id, Start: start, End: end, Strand: strand}
+}
+
+// Create a region from 1-based closed coordinates
+func NewRegion1(id string, start, end int, strand byte) Region {
+	return Region{Id: id, Start: start - 1, End: end, Strand: strand}
+}
+
+// Parse a region string: id, id:start or id:start-end (1-based, commas are
+// allowed) with an optional strand suffix, e.g., "chr1:1,000-2,000(-)"
+func ParseRegion(region string) (Region, error) {
+	r := Region{Id: region, End: -1}
+	str := region
+	if n := len(str); n > 3 && str[n-3] == '(' && str[n-1] == ')' {
+		if str[n-2] != '+' && str[n-2] != '-' {
+			return r, fmt.Errorf("[REGION]: %w (%s).", ErrInvalidRegion, region)
+		}
+		r.Strand = str[n-2]
+		str = str[:n-3]
+		r.Id = str
+	}
+	i := strings.LastIndexByte(str, ':')
+	if i < 0 {
+		return r, nil
+	}
+	r.Id = str[:i]
+	coord := strings.Replace(str[i+1:], ",", "", -1)
+	bounds := strings.SplitN(coord, "-", 2)
+	start, err := strconv.Atoi(bounds[0])
+	if err != nil || start < 1 {
+		return r, fmt.Errorf("[REGION]: %w (%s).", ErrInvalidRegion, region)
+	}
+	r.Start = start - 1
+	if len(bounds) == 2 {
+		r.End, err = strconv.Atoi(bounds[1])
+		if err != nil || r.End < start {
+			return r, fmt.Errorf("[REGION]: %w (%s).", ErrInvalidRegion, region)
+		}
+	}
+	return r, nil
+}
+
+// Region length (-1 if the region goes up to the end of the sequence)
+func (r Region) Length() int {
+	if r.End < 0 {
+		return -1
+	}
+	return r.End - r.Start
+}
+
+// Format the region with 1-based closed coordinates, e.g., "chr1:100-200(-)"
+func (r Region) String() string {
+	var b strings.Builder
+	b.WriteString(r.Id)
+	b.WriteByte(':')
+	b.WriteString(strconv.Itoa(r.Start + 1))
+	if r.End >= 0 {
+		b.WriteByte('-')
+		b.WriteString(strconv.Itoa(r.End))
+	}
+	if r.Strand == '+' || r.Strand == '-' {
+		b.WriteByte('(')
+		b.WriteByte(r.Strand)
+		b.WriteByte(')')
+	}
+	return b.String()
+}
+
+// Bound the region to a sequence length (End is set if it was -1), return
+// false if nothing remains
+func (r Region) Clip(length int) (Region, bool) {
+	if r.Start < 0 {
+		r.Start = 0
+	}
+	if r.End < 0 || r.End > length {
+		r.End = length
+	}
+	return r, r.Start < r.End
+}
+
+// Check the region against a sequence length and set End if it was -1
+func (r Region) check(length int) (Region, error) {
+	if r.End < 0 {
+		r.End = length
+	}
+	if r.Start < 0 || r.Start >= r.End {
+		return r, fmt.Errorf("[REGION]: %w (%s).", ErrInvalidRegion, r)
+	}
+	if r.End > length {
+		return r, fmt.Errorf("[REGION]: %w (%s, sequence length: %d).", ErrOutOfRange, r, length)
+	}
+	return r, nil
+}
+
+// Extract a subsequence from 0-based half-open coordinates
+// NOTE: the sequence and the quality are copied, the ID and the description
+// are kept and the features are dropped; out-of-range coordinates are
+// reported (see Region.Clip to bound them)
+func (s *Seq) Subseq(start, end int) (Seq, error) {
+	r, err := Region{Id: s.Id, Start: start, End: end}.check(s.Length())
+	if err != nil {
+		return Seq{}, err
+	}
+	sub := Seq{
+		Id:       s.Id,
+		Desc:     s.Desc,
+		Sequence: append([]byte(nil), s.Sequence[r.Start:r.End]...),
+		Alphabet: s.Alphabet,
+	}
+	sub.Quality.Phred = s.Quality.Phred
+	if len(s.Quality.StrScore) == s.Length() {
+		sub.Quality.StrScore = append([]byte(nil), s.Quality.StrScore[r.Start:r.End]...)
+	}
+	if len(s.Quality.IntScore) == s.Length() {
+		sub.Quality.IntScore = append([]int(nil), s.Quality.IntScore[r.Start:r.End]...)
+	}
+	return sub, nil
+}
+
+// Extract a region of the sequence (reverse-complemented on the - strand)
+// NOTE: the ID of the subsequence is the region (e.g., "chr1:100-200(-)")
+// and the description is kept; the region ID must be empty or match the
+// sequence ID
+func (s *Seq) Region(r Region) (Seq, error) {
+	if r.Id == "" {
+		r.Id = s.Id
+	} else if r.Id != s.Id {
+		return Seq{}, fmt.Errorf("[REGION]: %w (%s, sequence: %s).", ErrInvalidRegion, r, s.Id)
+	}
+	r, err := r.check(s.Length())
+	if err != nil {
+		return Seq{}, err
+	}
+	sub, _ := s.Subseq(r.Start, r.End)
+	if r.Strand == '-' {
+		sub.ReverseComplement()
+	}
+	sub.Id = r.String()
+	return sub, nil
+}
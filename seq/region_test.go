@@ -0,0 +1,192 @@
+package seq
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/hdevillers/go-seq/feature"
+	"github.com/hdevillers/go-seq/quality"
+)
+
+func TestParseRegion(t *testing.T) {
+	tests := []struct {
+		region string
+		r      Region
+		str    string
+	}{
+		{"chr1", Region{"chr1", 0, -1, 0}, "chr1:1"},
+		{"chr1(-)", Region{"chr1", 0, -1, '-'}, "chr1:1(-)"},
+		{"chr1:1", Region{"chr1", 0, -1, 0}, "chr1:1"},
+		{"chr1:5", Region{"chr1", 4, -1, 0}, "chr1:5"},
+		{"chr1:1-1", Region{"chr1", 0, 1, 0}, "chr1:1-1"},
+		{"chr1:5-10", Region{"chr1", 4, 10, 0}, "chr1:5-10"},
+		{"chr1:5-10(+)", Region{"chr1", 4, 10, '+'}, "chr1:5-10(+)"},
+		{"chr1:5-10(-)", Region{"chr1", 4, 10, '-'}, "chr1:5-10(-)"},
+		{"chr1:1,000-2,000", Region{"chr1", 999, 2000, 0}, "chr1:1000-2000"},
+		{"HLA:A:1-5", Region{"HLA:A", 0, 5, 0}, "HLA:A:1-5"},
+	}
+	for _, tt := range tests {
+		r, err := ParseRegion(tt.region)
+		if err != nil || r != tt.r {
+			t.Errorf("ParseRegion(%s) = %+v, %v, want %+v", tt.region, r, err, tt.r)
+			continue
+		}
+		if r.String() != tt.str {
+			t.Errorf("%s: String = %s, want %s", tt.region, r.String(), tt.str)
+		}
+
+		// Round trip
+		if r2, err := ParseRegion(r.String()); err != nil || r2 != r {
+			t.Errorf("%s: ParseRegion(%s) = %+v, %v", tt.region, r.String(), r2, err)
+		}
+	}
+	for _, region := range []string{"chr1:0-5", "chr1:x", "chr1:-5", "chr1:10-5", "chr1:5-x", "chr1:5-10(x)", "chr1:5-10()"} {
+		if _, err := ParseRegion(region); !errors.Is(err, ErrInvalidRegion) {
+			t.Errorf("ParseRegion(%s): error %v", region, err)
+		}
+	}
+}
+
+func TestNewRegion1(t *testing.T) {
+	r := NewRegion1("chr1", 1, 10, '-')
+	if r != NewRegion("chr1", 0, 10, '-') || r.Length() != 10 || r.String() != "chr1:1-10(-)" {
+		t.Errorf("NewRegion1 = %+v (%s)", r, r)
+	}
+	if r = NewRegion1("chr1", 3, 3, 0); r.Length() != 1 || r.String() != "chr1:3-3" {
+		t.Errorf("NewRegion1 = %+v (%s)", r, r)
+	}
+	if r = (Region{Id: "chr1", End: -1}); r.Length() != -1 {
+		t.Errorf("Length = %d", r.Length())
+	}
+}
+
+func TestCheckRegion(t *testing.T) {
+	tests := []struct {
+		r   Region
+		end int
+		err error
+	}{
+		{Region{"s", 0, 10, 0}, 10, nil},
+		{Region{"s", 0, -1, 0}, 10, nil},
+		{Region{"s", 9, 10, 0}, 10, nil},
+		{Region{"s", 9, -1, 0}, 10, nil},
+		{Region{"s", 0, 11, 0}, 11, ErrOutOfRange},
+		{Region{"s", 10, 11, 0}, 11, ErrOutOfRange},
+		{Region{"s", 10, -1, 0}, 10, ErrInvalidRegion},
+		{Region{"s", 5, 5, 0}, 5, ErrInvalidRegion},
+		{Region{"s", 6, 5, 0}, 5, ErrInvalidRegion},
+		{Region{"s", -1, 5, 0}, 5, ErrInvalidRegion},
+	}
+	for _, tt := range tests {
+		r, err := tt.r.check(10)
+		if !errors.Is(err, tt.err) || (tt.err == nil && err != nil) || r.End != tt.end {
+			t.Errorf("check(%+v) = %+v, %v, want %v", tt.r, r, err, tt.err)
+		}
+	}
+}
+
+func TestClip(t *testing.T) {
+	tests := []struct {
+		r, clip Region
+		ok      bool
+	}{
+		{Region{"s", 2, 5, 0}, Region{"s", 2, 5, 0}, true},
+		{Region{"s", -2, 12, '-'}, Region{"s", 0, 10, '-'}, true},
+		{Region{"s", 3, -1, 0}, Region{"s", 3, 10, 0}, true},
+		{Region{"s", 10, 12, 0}, Region{"s", 10, 10, 0}, false},
+	}
+	for _, tt := range tests {
+		if r, ok := tt.r.Clip(10); r != tt.clip || ok != tt.ok {
+			t.Errorf("Clip(%+v) = %+v, %v", tt.r, r, ok)
+		}
+	}
+}
+
+// Sequence (length 10) with quality and features
+func regionSeq() Seq {
+	return Seq{
+		Id:       "s",
+		Desc:     "desc",
+		Sequence: []byte("AACCGGTTAC"),
+		Quality:  quality.Quality{Phred: 33, StrScore: []byte("ABCDEFGHIJ")},
+		Features: []feature.Feature{{Key: "gene", Location: "1..10"}},
+	}
+}
+
+func TestSubseq(t *testing.T) {
+	s := regionSeq()
+	sub, err := s.Subseq(0, 10)
+	if err != nil || string(sub.Sequence) != "AACCGGTTAC" || string(sub.Quality.StrScore) != "ABCDEFGHIJ" {
+		t.Errorf("Subseq(0, 10) = %s %s, %v", sub.Sequence, sub.Quality.StrScore, err)
+	}
+	sub, err = s.Subseq(2, 5)
+	if err != nil || sub.Id != "s" || sub.Desc != "desc" || string(sub.Sequence) != "CCG" ||
+		string(sub.Quality.StrScore) != "CDE" || sub.Quality.Phred != 33 || sub.Features != nil {
+		t.Errorf("Subseq(2, 5) = %+v, %v", sub, err)
+	}
+
+	// The subsequence is a copy
+	sub.Sequence[0] = 'N'
+	sub.Quality.StrScore[0] = '!'
+	if !reflect.DeepEqual(s, regionSeq()) {
+		t.Errorf("the sequence was modified: %+v", s)
+	}
+
+	// Quality of another length
+	s.Quality.StrScore = s.Quality.StrScore[:4]
+	if sub, err = s.Subseq(1, 3); err != nil || sub.Quality.StrScore != nil {
+		t.Errorf("Subseq(1, 3) = %s, %v", sub.Quality.StrScore, err)
+	}
+	if _, err = s.Subseq(5, 11); !errors.Is(err, ErrOutOfRange) {
+		t.Errorf("Subseq(5, 11): error %v", err)
+	}
+	if _, err = s.Subseq(5, 5); !errors.Is(err, ErrInvalidRegion) {
+		t.Errorf("Subseq(5, 5): error %v", err)
+	}
+}
+
+func TestSeqRegion(t *testing.T) {
+	tests := []struct {
+		region, id, seq, qual string
+	}{
+		{"s:1-10", "s:1-10", "AACCGGTTAC", "ABCDEFGHIJ"},
+		{"s", "s:1-10", "AACCGGTTAC", "ABCDEFGHIJ"},
+		{"s:1-1", "s:1-1", "A", "A"},
+		{"s:10", "s:10-10", "C", "J"},
+		{"s:3-5(+)", "s:3-5(+)", "CCG", "CDE"},
+		{"s:3-5(-)", "s:3-5(-)", "CGG", "EDC"},
+		{"s:1-10(-)", "s:1-10(-)", "GTAACCGGTT", "JIHGFEDCBA"},
+	}
+	s := regionSeq()
+	for _, tt := range tests {
+		r, err := ParseRegion(tt.region)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sub, err := s.Region(r)
+		if err != nil || sub.Id != tt.id || sub.Desc != "desc" || string(sub.Sequence) != tt.seq || string(sub.Quality.StrScore) != tt.qual || sub.Features != nil {
+			t.Errorf("Region(%s) = %s %s %s, %v", tt.region, sub.Id, sub.Sequence, sub.Quality.StrScore, err)
+		}
+	}
+
+	// Region without ID
+	if sub, err := s.Region(NewRegion("", 8, 10, '-')); err != nil || sub.Id != "s:9-10(-)" || string(sub.Sequence) != "GT" {
+		t.Errorf("Region without ID = %s %s, %v", sub.Id, sub.Sequence, err)
+	}
+
+	bad := []struct {
+		r   Region
+		err error
+	}{
+		{NewRegion("other", 0, 5, 0), ErrInvalidRegion},
+		{NewRegion1("s", 5, 11, '-'), ErrOutOfRange},
+		{NewRegion1("s", 11, -1, 0), ErrInvalidRegion},
+		{NewRegion1("s", 0, 5, 0), ErrInvalidRegion},
+	}
+	for _, tt := range bad {
+		if _, err := s.Region(tt.r); !errors.Is(err, tt.err) {
+			t.Errorf("Region(%s): error %v, want %v", tt.r, err, tt.err)
+		}
+	}
+}
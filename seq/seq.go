@@ -1,6 +1,8 @@
 package seq
 
 import (
+	"github.com/hdevillers/go-seq/alphabet"
+	"github.com/hdevillers/go-seq/feature"
 	"github.com/hdevillers/go-seq/quality"
 )
 
@@ -9,6 +11,8 @@ type Seq struct {
 	Desc     string
 	Sequence []byte
 	Quality  quality.Quality
+	Features []feature.Feature
+	Alphabet *alphabet.Alphabet
 }
 
 func NewSeq(id string) *Seq {
@@ -32,6 +36,38 @@ func (s *Seq) AppendSequence(sequence []byte) {
 	s.Sequence = append(s.Sequence, sequence...)
 }
 
+func (s *Seq) AddFeature(f feature.Feature) {
+	s.Features = append(s.Features, f)
+}
+
+func (s *Seq) SetAlphabet(a *alphabet.Alphabet) {
+	s.Alphabet = a
+}
+
+// Guess and set the alphabet from the sequence content (nil if unknown)
+func (s *Seq) GuessAlphabet() *alphabet.Alphabet {
+	s.Alphabet = alphabet.Guess(s.Sequence)
+	return s.Alphabet
+}
+
+// Check the sequence characters against its alphabet (if any)
+func (s *Seq) Validate() error {
+	if s.Alphabet == nil {
+		return nil
+	}
+	return s.Alphabet.Validate(s.Sequence)
+}
+
 func (s *Seq) Length() int {
 	return len(s.Sequence)
 }
+
+// Clear the sequence but keep the allocated memory (record reuse)
+func (s *Seq) Reset() {
+	s.Id = ""
+	s.Desc = ""
+	s.Sequence = s.Sequence[:0]
+	s.Quality.Reset()
+	s.Features = s.Features[:0]
+	s.Alphabet = nil
+}
@@ -0,0 +1,133 @@
+package seq
+
+import (
+	"github.com/hdevillers/go-seq/alphabet"
+	"github.com/hdevillers/go-seq/feature"
+)
+
+// Complement tables (IUPAC codes, case preserved)
+// NOTE: the tables only differ by the complement of A (T or U)
+var dnaComplement, rnaComplement = func() ([256]byte, [256]byte) {
+	var c [256]byte
+	for i := range c {
+		c[i] = byte(i)
+	}
+	pairs := []string{"AT", "CG", "RY", "KM", "BV", "DH", "UA"}
+	for _, p := range pairs {
+		c[p[0]] = p[1]
+		c[p[0]+'a'-'A'] = p[1] + 'a' - 'A'
+		if p != "UA" {
+			c[p[1]] = p[0]
+			c[p[1]+'a'-'A'] = p[0] + 'a' - 'A'
+		}
+	}
+	r := c
+	r['A'], r['a'] = 'U', 'u'
+	return c, r
+}()
+
+// Complement a DNA sequence (in place)
+func ComplementBytes(b []byte) {
+	for i, c := range b {
+		b[i] = dnaComplement[c]
+	}
+}
+
+// Reverse-complement a DNA sequence (in place)
+func ReverseComplementBytes(b []byte) {
+	for i, j := 0, len(b)-1; i <= j; i, j = i+1, j-1 {
+		b[i], b[j] = dnaComplement[b[j]], dnaComplement[b[i]]
+	}
+}
+
+// Return true if the sequence is RNA (alphabet or U without T)
+func (s *Seq) isRNA() bool {
+	if s.Alphabet != nil {
+		return s.Alphabet == alphabet.RNA
+	}
+	u := false
+	for _, c := range s.Sequence {
+		switch c {
+		case 'T', 't':
+			return false
+		case 'U', 'u':
+			u = true
+		}
+	}
+	return u
+}
+
+// Get the complement table of the sequence
+func (s *Seq) complementTable() *[256]byte {
+	if s.isRNA() {
+		return &rnaComplement
+	}
+	return &dnaComplement
+}
+
+// Complement the sequence (in place)
+// NOTE: IUPAC codes and soft-masking (lower case) are supported, RNA
+// sequences are complemented with U
+func (s *Seq) Complement() {
+	t := s.complementTable()
+	for i, c := range s.Sequence {
+		s.Sequence[i] = t[c]
+	}
+}
+
+// Reverse the sequence and its quality (in place)
+// NOTE: the features are removed (their locations are not converted)
+func (s *Seq) Reverse() {
+	reverseBytes(s.Sequence)
+	reverseBytes(s.Quality.StrScore)
+	q := s.Quality.IntScore
+	for i, j := 0, len(q)-1; i < j; i, j = i+1, j-1 {
+		q[i], q[j] = q[j], q[i]
+	}
+	s.Features = nil
+}
+
+// Reverse-complement the sequence and reverse its quality (in place)
+func (s *Seq) ReverseComplement() {
+	s.Reverse()
+	s.Complement()
+}
+
+// Get a copy of the sequence (sequence, quality and features are copied)
+func (s *Seq) Copy() Seq {
+	c := *s
+	c.Sequence = append([]byte(nil), s.Sequence...)
+	c.Quality.StrScore = append([]byte(nil), s.Quality.StrScore...)
+	c.Quality.IntScore = append([]int(nil), s.Quality.IntScore...)
+	if s.Features != nil {
+		c.Features = append([]feature.Feature(nil), s.Features...)
+	}
+	return c
+}
+
+// Get the complement of the sequence
+func (s *Seq) ComplementCopy() Seq {
+	c := s.Copy()
+	c.Complement()
+	return c
+}
+
+// Get the reverse of the sequence
+func (s *Seq) ReverseCopy() Seq {
+	c := s.Copy()
+	c.Reverse()
+	return c
+}
+
+// Get the reverse-complement of the sequence
+func (s *Seq) ReverseComplementCopy() Seq {
+	c := s.Copy()
+	c.ReverseComplement()
+	return c
+}
+
+func reverseBytes(b []byte) {
+	for i, j := 0, len(b)-1; i < j; i, j = i+1, j-1 {
+		b[i], b[j] = b[j], b[i]
+	}
+}
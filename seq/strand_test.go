@@ -0,0 +1,135 @@
+package seq
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hdevillers/go-seq/alphabet"
+	"github.com/hdevillers/go-seq/feature"
+	"github.com/hdevillers/go-seq/quality"
+)
+
+func TestComplementBytes(t *testing.T) {
+	tests := []struct {
+		seq, comp, revcomp string
+	}{
+		{"", "", ""},
+		{"A", "T", "T"},
+		{"ACGT", "TGCA", "ACGT"},
+		{"AACGT", "TTGCA", "ACGTT"},
+		{"RYKMBVDHNSW", "YRMKVBHDNSW", "WSNDHBVKMRY"},
+		{"acgtNnryKM", "tgcaNnyrMK", "KMrynNacgt"},
+		{"AC-GT.*X", "TG-CA.*X", "X*.AC-GT"},
+		{"AU", "TA", "AT"},
+	}
+	for _, tt := range tests {
+		b := []byte(tt.seq)
+		ComplementBytes(b)
+		if string(b) != tt.comp {
+			t.Errorf("ComplementBytes(%s) = %s, want %s", tt.seq, b, tt.comp)
+		}
+		b = []byte(tt.seq)
+		ReverseComplementBytes(b)
+		if string(b) != tt.revcomp {
+			t.Errorf("ReverseComplementBytes(%s) = %s, want %s", tt.seq, b, tt.revcomp)
+		}
+	}
+}
+
+func TestComplement(t *testing.T) {
+	tests := []struct {
+		seq      string
+		alphabet *alphabet.Alphabet
+		comp     string
+	}{
+		{"ACGTN", nil, "TGCAN"},
+		{"AUGCu", nil, "UACGa"},
+		{"acgu", nil, "ugca"},
+		{"AAGC", alphabet.RNA, "UUCG"},
+		{"AAGC", nil, "TTCG"},
+		{"ATU", nil, "TAA"},
+		{"AUU", alphabet.DNA, "TAA"},
+		{"RYkmBVdh", nil, "YRmkVBhd"},
+	}
+	for _, tt := range tests {
+		s := Seq{Sequence: []byte(tt.seq), Alphabet: tt.alphabet}
+		s.Complement()
+		if string(s.Sequence) != tt.comp {
+			t.Errorf("Complement(%s) = %s, want %s", tt.seq, s.Sequence, tt.comp)
+		}
+	}
+}
+
+// RNA sequence with quality and features
+func strandSeq() Seq {
+	return Seq{
+		Id:       "s",
+		Sequence: []byte("AACGu"),
+		Quality:  quality.Quality{Phred: 33, StrScore: []byte("ABCDE"), IntScore: []int{32, 33, 34, 35, 36}},
+		Features: []feature.Feature{{Key: "gene", Location: "1..3"}},
+	}
+}
+
+func TestReverse(t *testing.T) {
+	tests := []struct {
+		name string
+		op   func(*Seq)
+		seq  string
+	}{
+		{"Reverse", (*Seq).Reverse, "uGCAA"},
+		{"ReverseComplement", (*Seq).ReverseComplement, "aCGUU"},
+	}
+	for _, tt := range tests {
+		s := strandSeq()
+		tt.op(&s)
+		if string(s.Sequence) != tt.seq {
+			t.Errorf("%s: %s, want %s", tt.name, s.Sequence, tt.seq)
+		}
+		if string(s.Quality.StrScore) != "EDCBA" || !reflect.DeepEqual(s.Quality.IntScore, []int{36, 35, 34, 33, 32}) {
+			t.Errorf("%s: quality %s %v", tt.name, s.Quality.StrScore, s.Quality.IntScore)
+		}
+		if s.Features != nil {
+			t.Errorf("%s: features %v", tt.name, s.Features)
+		}
+	}
+
+	// Complement keeps the quality and the features
+	s := strandSeq()
+	s.Complement()
+	if string(s.Quality.StrScore) != "ABCDE" || len(s.Features) != 1 {
+		t.Errorf("Complement: quality %s, features %v", s.Quality.StrScore, s.Features)
+	}
+}
+
+func TestCopy(t *testing.T) {
+	tests := []struct {
+		name     string
+		op       func(*Seq) Seq
+		seq      string
+		qual     string
+		features int
+	}{
+		{"Copy", (*Seq).Copy, "AACGu", "ABCDE", 1},
+		{"ComplementCopy", (*Seq).ComplementCopy, "UUGCa", "ABCDE", 1},
+		{"ReverseCopy", (*Seq).ReverseCopy, "uGCAA", "EDCBA", 0},
+		{"ReverseComplementCopy", (*Seq).ReverseComplementCopy, "aCGUU", "EDCBA", 0},
+	}
+	for _, tt := range tests {
+		s := strandSeq()
+		c := tt.op(&s)
+		if c.Id != "s" || string(c.Sequence) != tt.seq || string(c.Quality.StrScore) != tt.qual || len(c.Features) != tt.features {
+			t.Errorf("%s: %s %s %s %v", tt.name, c.Id, c.Sequence, c.Quality.StrScore, c.Features)
+		}
+
+		// The copy does not share memory with the original sequence
+		c.Sequence[0] = 'N'
+		c.Quality.StrScore[0] = '!'
+		c.Quality.IntScore[0] = 0
+		if c.Features != nil {
+			c.Features[0].Key = "CDS"
+		}
+		if !reflect.DeepEqual(s, strandSeq()) {
+			t.Errorf("%s: the original sequence was modified: %+v", tt.name, s)
+		}
+	}
+}
@@ -0,0 +1,411 @@
+package bam
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+
+	"github.com/hdevillers/go-seq/seq"
+	"github.com/hdevillers/go-seq/seqio/bgzf"
+	"github.com/hdevillers/go-seq/seqio/sam"
+	"github.com/hdevillers/go-seq/seqio/seqitf"
+)
+
+/*
+	BAM is the binary (little-endian) version of SAM, compressed with
+	BGZF. Records are decoded into the same alignment records as the
+	SAM reader.
+*/
+
+const (
+	Magic      string = "BAM\x01"
+	seqCodes   string = "=ACMGRSVTWYHKDBN"
+	fixedBytes int    = 32
+)
+
+// BAM alignment reader struct
+type Reader struct {
+	bgz     *bgzf.Reader
+	header  sam.Header
+	refs    []sam.Reference
+	buf     []byte
+	started bool
+	eof     bool
+	count   int
+}
+
+// Generate a new reader (from a BGZF compressed stream)
+func NewReader(r io.Reader) *Reader {
+	return &Reader{
+		bgz:     bgzf.NewReader(r),
+		started: false,
+		eof:     false,
+	}
+}
+
+// Return true if reachs the end-of-file
+func (r *Reader) IsEOF() bool {
+	return r.eof
+}
+
+// Locate an error in the input (no line in binary files)
+func (r *Reader) parseError(err error) error {
+	return seqitf.NewParseError("BAM", 0, r.count+1, err)
+}
+
+func (r *Reader) readInt32() (int32, error) {
+	var b [4]byte
+	_, err := io.ReadFull(r.bgz, b[:])
+	return int32(binary.LittleEndian.Uint32(b[:])), err
+}
+
+// Parse the header (text and reference dictionary)
+func (r *Reader) readHeader() error {
+	err := r.parseHeader()
+	if err != nil {
+		return r.parseError(err)
+	}
+	return nil
+}
+
+func (r *Reader) parseHeader() error {
+	r.started = true
+	magic := make([]byte, 4)
+	_, err := io.ReadFull(r.bgz, magic)
+	if err != nil || string(magic) != Magic {
+		return errors.New("Invalid BAM magic number")
+	}
+	ltext, err := r.readInt32()
+	if err != nil || ltext < 0 {
+		return errors.New("Truncated header")
+	}
+	text := make([]byte, ltext)
+	_, err = io.ReadFull(r.bgz, text)
+	if err != nil {
+		return errors.New("Truncated header")
+	}
+	r.header, err = sam.ParseHeader(string(text))
+	if err != nil {
+		return err
+	}
+
+	nref, err := r.readInt32()
+	if err != nil || nref < 0 {
+		return errors.New("Truncated reference dictionary")
+	}
+	r.refs = make([]sam.Reference, nref)
+	for i := range r.refs {
+		lname, err := r.readInt32()
+		if err != nil || lname < 1 {
+			return errors.New("Truncated reference dictionary")
+		}
+		name := make([]byte, lname)
+		_, err = io.ReadFull(r.bgz, name)
+		if err != nil {
+			return errors.New("Truncated reference dictionary")
+		}
+		lref, err := r.readInt32()
+		if err != nil {
+			return errors.New("Truncated reference dictionary")
+		}
+		r.refs[i] = sam.Reference{Name: string(name[:lname-1]), Length: int(lref)}
+	}
+
+	// The binary dictionary is authoritative (as in samtools), the @SQ lines
+	// of the text header are still available in Lines
+	r.header.References = r.refs
+	return nil
+}
+
+// Get the BAM header
+func (r *Reader) Header() (sam.Header, error) {
+	if !r.started {
+		err := r.readHeader()
+		if err != nil {
+			return r.header, err
+		}
+	}
+	return r.header, nil
+}
+
+// Get a reference name from its ID
+func (r *Reader) refName(id int32) (string, error) {
+	if id == -1 {
+		return "*", nil
+	}
+	if id < 0 || int(id) >= len(r.refs) {
+		return "", errors.New("Invalid reference ID")
+	}
+	return r.refs[id].Name, nil
+}
+
+// Read the next alignment record
+// NOTE: at the end of the file, an empty record is returned with no error
+func (r *Reader) ReadRecord() (sam.Record, error) {
+	var a sam.Record
+	if !r.started {
+		err := r.readHeader()
+		if err != nil {
+			return a, err
+		}
+	}
+
+	// Block size (EOF is only valid here)
+	var b [4]byte
+	n, err := io.ReadFull(r.bgz, b[:])
+	if err == io.EOF && n == 0 {
+		r.eof = true
+		return a, nil
+	}
+	if err != nil {
+		return a, r.parseError(errors.New("Truncated alignment record"))
+	}
+	size := int(binary.LittleEndian.Uint32(b[:]))
+	if size < fixedBytes {
+		return a, r.parseError(errors.New("Invalid alignment record size"))
+	}
+	if cap(r.buf) < size {
+		r.buf = make([]byte, size)
+	}
+	data := r.buf[:size]
+	_, err = io.ReadFull(r.bgz, data)
+	if err != nil {
+		return a, r.parseError(errors.New("Truncated alignment record"))
+	}
+	a, err = r.decode(data)
+	if err != nil {
+		return a, r.parseError(err)
+	}
+	r.count++
+	return a, nil
+}
+
+// Decode an alignment record (without its block size)
+func (r *Reader) decode(data []byte) (sam.Record, error) {
+	var a sam.Record
+	var err error
+	le := binary.LittleEndian
+
+	refID := int32(le.Uint32(data[0:4]))
+	pos := int32(le.Uint32(data[4:8]))
+	lname := int(data[8])
+	a.MapQ = int(data[9])
+	ncigar := int(le.Uint16(data[12:14]))
+	a.Flag = le.Uint16(data[14:16])
+	lseq := int(int32(le.Uint32(data[16:20])))
+	nextID := int32(le.Uint32(data[20:24]))
+	a.PNext = int(int32(le.Uint32(data[24:28]))) + 1
+	a.TLen = int(int32(le.Uint32(data[28:32])))
+	a.Pos = int(pos) + 1
+
+	a.RName, err = r.refName(refID)
+	if err != nil {
+		return a, err
+	}
+	if nextID == refID && refID != -1 {
+		a.RNext = "="
+	} else {
+		a.RNext, err = r.refName(nextID)
+		if err != nil {
+			return a, err
+		}
+	}
+
+	// Variable length data
+	p := fixedBytes
+	if lname < 1 || lseq < 0 || p+lname+4*ncigar+(lseq+1)/2+lseq > len(data) {
+		return a, errors.New("Inconsistent alignment record sizes")
+	}
+	a.QName = string(data[p : p+lname-1])
+	p += lname
+	for i := 0; i < ncigar; i++ {
+		v := le.Uint32(data[p : p+4])
+		op := int(v & 0xf)
+		if op >= len(sam.CigarOps) {
+			return a, errors.New("Invalid CIGAR operation")
+		}
+		a.Cigar = append(a.Cigar, sam.CigarOp{Op: sam.CigarOps[op], Len: int(v >> 4)})
+		p += 4
+	}
+	if lseq > 0 {
+		a.Seq = make([]byte, lseq)
+		for i := 0; i < lseq; i++ {
+			c := data[p+i/2]
+			if i%2 == 0 {
+				c >>= 4
+			}
+			a.Seq[i] = seqCodes[c&0xf]
+		}
+		p += (lseq + 1) / 2
+		if data[p] != 0xff {
+			a.Qual = make([]byte, lseq)
+			for i := 0; i < lseq; i++ {
+				a.Qual[i] = data[p+i] + 33
+			}
+		}
+		p += lseq
+	}
+
+	// Optional fields
+	for p < len(data) {
+		var t sam.Tag
+		t, p, err = decodeTag(data, p)
+		if err != nil {
+			return a, err
+		}
+		a.Tags = append(a.Tags, t)
+	}
+
+	// Long CIGAR stored in the CG tag (placeholder: <lseq>S<rlen>N)
+	if len(a.Cigar) == 2 && a.Cigar[0].Op == 'S' && a.Cigar[0].Len == lseq && a.Cigar[1].Op == 'N' {
+		for i, t := range a.Tags {
+			if t.Name != "CG" || t.Type != 'B' {
+				continue
+			}
+			ops, ok := t.Value.([]int)
+			if !ok {
+				break
+			}
+			a.Cigar = a.Cigar[:0]
+			for _, v := range ops {
+				a.Cigar = append(a.Cigar, sam.CigarOp{Op: sam.CigarOps[v&0xf], Len: v >> 4})
+			}
+			a.Tags = append(a.Tags[:i], a.Tags[i+1:]...)
+			break
+		}
+	}
+
+	return a, nil
+}
+
+// Size of the numerical types
+func typeSize(t byte) int {
+	switch t {
+	case 'A', 'c', 'C':
+		return 1
+	case 's', 'S':
+		return 2
+	case 'i', 'I', 'f':
+		return 4
+	}
+	return 0
+}
+
+// Decode a numerical value (integers are returned as int, floats as float64)
+func decodeValue(t byte, b []byte) interface{} {
+	le := binary.LittleEndian
+	switch t {
+	case 'c':
+		return int(int8(b[0]))
+	case 'C':
+		return int(b[0])
+	case 's':
+		return int(int16(le.Uint16(b)))
+	case 'S':
+		return int(le.Uint16(b))
+	case 'i':
+		return int(int32(le.Uint32(b)))
+	case 'I':
+		return int(le.Uint32(b))
+	case 'f':
+		return float64(math.Float32frombits(le.Uint32(b)))
+	}
+	return nil
+}
+
+// Decode an optional field starting at p, return the next position
+func decodeTag(data []byte, p int) (sam.Tag, int, error) {
+	var t sam.Tag
+	if p+3 > len(data) {
+		return t, p, errors.New("Truncated optional field")
+	}
+	t.Name = string(data[p : p+2])
+	typ := data[p+2]
+	p += 3
+
+	switch typ {
+	case 'A':
+		if p+1 > len(data) {
+			return t, p, errors.New("Truncated optional field")
+		}
+		t.Type = 'A'
+		t.Value = string(data[p : p+1])
+		p++
+	case 'c', 'C', 's', 'S', 'i', 'I':
+		n := typeSize(typ)
+		if p+n > len(data) {
+			return t, p, errors.New("Truncated optional field")
+		}
+		t.Type = 'i'
+		t.Value = decodeValue(typ, data[p:p+n])
+		p += n
+	case 'f':
+		if p+4 > len(data) {
+			return t, p, errors.New("Truncated optional field")
+		}
+		t.Type = 'f'
+		t.Value = decodeValue(typ, data[p:p+4])
+		p += 4
+	case 'Z', 'H':
+		end := p
+		for end < len(data) && data[end] != 0 {
+			end++
+		}
+		if end == len(data) {
+			return t, p, errors.New("Unterminated string field")
+		}
+		t.Type = typ
+		t.Value = string(data[p:end])
+		p = end + 1
+	case 'B':
+		if p+5 > len(data) {
+			return t, p, errors.New("Truncated optional field")
+		}
+		sub := data[p]
+		count := int(binary.LittleEndian.Uint32(data[p+1 : p+5]))
+		p += 5
+		n := typeSize(sub)
+		if n == 0 || sub == 'A' || count < 0 || p+n*count > len(data) {
+			return t, p, errors.New("Invalid array field")
+		}
+		t.Type = 'B'
+		t.SubType = sub
+		if sub == 'f' {
+			a := make([]float64, count)
+			for i := range a {
+				a[i] = decodeValue(sub, data[p:p+4]).(float64)
+				p += 4
+			}
+			t.Value = a
+		} else {
+			a := make([]int, count)
+			for i := range a {
+				a[i] = decodeValue(sub, data[p:p+n]).(int)
+				p += n
+			}
+			t.Value = a
+		}
+	default:
+		return t, p, errors.New("Unknown optional field type")
+	}
+	return t, p, nil
+}
+
+// Read the next read as a sequence
+// NOTE: secondary and supplementary alignments are skipped
+func (r *Reader) Read() (seq.Seq, error) {
+	for {
+		a, err := r.ReadRecord()
+		if err != nil || r.eof {
+			return seq.Seq{}, err
+		}
+		if a.HasFlag(sam.FlagSecondary | sam.FlagSupplementary) {
+			continue
+		}
+		if len(a.Seq) == 0 {
+			return seq.Seq{}, seqitf.NewParseError("BAM", 0, r.count, errors.New("Primary alignment without sequence ("+a.QName+")"))
+		}
+		return a.ToSeq(), nil
+	}
+}
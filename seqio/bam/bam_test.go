@@ -0,0 +1,305 @@
+package bam
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hdevillers/go-seq/seqio/bgzf"
+	"github.com/hdevillers/go-seq/seqio/sam"
+)
+
+// Alignment record to encode (qual nil is stored as 0xff)
+type testRecord struct {
+	refID  int32
+	pos    int32
+	name   string
+	mapq   byte
+	flag   uint16
+	cigar  []uint32
+	seq    string
+	qual   []byte
+	nextID int32
+	pnext  int32
+	tlen   int32
+	tags   []byte
+}
+
+// Encode a CIGAR operation
+func cigarOp(n int, op byte) uint32 {
+	return uint32(n)<<4 | uint32(strings.IndexByte(sam.CigarOps, op))
+}
+
+// Encode an alignment record (without its block size)
+func (t testRecord) encode() []byte {
+	le := binary.LittleEndian
+	var b bytes.Buffer
+	var fixed [fixedBytes]byte
+	le.PutUint32(fixed[0:], uint32(t.refID))
+	le.PutUint32(fixed[4:], uint32(t.pos))
+	fixed[8] = byte(len(t.name) + 1)
+	fixed[9] = t.mapq
+	le.PutUint16(fixed[12:], uint16(len(t.cigar)))
+	le.PutUint16(fixed[14:], t.flag)
+	le.PutUint32(fixed[16:], uint32(len(t.seq)))
+	le.PutUint32(fixed[20:], uint32(t.nextID))
+	le.PutUint32(fixed[24:], uint32(t.pnext))
+	le.PutUint32(fixed[28:], uint32(t.tlen))
+	b.Write(fixed[:])
+	b.WriteString(t.name)
+	b.WriteByte(0)
+	for _, c := range t.cigar {
+		binary.Write(&b, le, c)
+	}
+	packed := make([]byte, (len(t.seq)+1)/2)
+	for i := 0; i < len(t.seq); i++ {
+		c := byte(strings.IndexByte(seqCodes, t.seq[i]))
+		if i%2 == 0 {
+			c <<= 4
+		}
+		packed[i/2] |= c
+	}
+	b.Write(packed)
+	if t.qual == nil {
+		b.Write(bytes.Repeat([]byte{0xff}, len(t.seq)))
+	} else {
+		b.Write(t.qual)
+	}
+	b.Write(t.tags)
+	return b.Bytes()
+}
+
+// Encode an array tag
+func arrayTag(name string, sub byte, values ...interface{}) []byte {
+	var b bytes.Buffer
+	b.WriteString(name)
+	b.WriteByte('B')
+	b.WriteByte(sub)
+	binary.Write(&b, binary.LittleEndian, uint32(len(values)))
+	for _, v := range values {
+		binary.Write(&b, binary.LittleEndian, v)
+	}
+	return b.Bytes()
+}
+
+func testReader() *Reader {
+	return &Reader{
+		started: true,
+		refs:    []sam.Reference{{Name: "chr1", Length: 1000}, {Name: "chr2", Length: 500}},
+	}
+}
+
+func TestDecodeSequence(t *testing.T) {
+	tests := []struct {
+		seq  string
+		qual []byte
+	}{
+		{"ACGTN", []byte{30, 31, 32, 33, 2}},
+		{"ACGT", []byte{40, 40, 40, 40}},
+		{"=ACMGRSVTWYHKDBN", nil},
+		{"G", []byte{0}},
+	}
+	r := testReader()
+	for _, tt := range tests {
+		rec := testRecord{refID: 0, pos: 9, name: "r1", cigar: []uint32{cigarOp(len(tt.seq), 'M')}, seq: tt.seq, qual: tt.qual, nextID: -1, pnext: -1}
+		a, err := r.decode(rec.encode())
+		if err != nil {
+			t.Fatalf("%s: %v", tt.seq, err)
+		}
+		if string(a.Seq) != tt.seq {
+			t.Errorf("Seq = %q, want %q", a.Seq, tt.seq)
+		}
+		if tt.qual == nil {
+			if a.Qual != nil {
+				t.Errorf("%s: Qual = %q, want nil (0xff)", tt.seq, a.Qual)
+			}
+			continue
+		}
+		for i, q := range tt.qual {
+			if a.Qual[i] != q+33 {
+				t.Errorf("%s: Qual[%d] = %d, want %d", tt.seq, i, a.Qual[i], q+33)
+			}
+		}
+	}
+}
+
+func TestDecodeFields(t *testing.T) {
+	r := testReader()
+	rec := testRecord{
+		refID: 1, pos: 99, name: "read/1", mapq: 60, flag: 0x63,
+		cigar:  []uint32{cigarOp(2, 'S'), cigarOp(3, 'M')},
+		seq:    "ACGTA",
+		nextID: 1, pnext: 199, tlen: 150,
+	}
+	a, err := r.decode(rec.encode())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.QName != "read/1" || a.RName != "chr2" || a.Pos != 100 || a.MapQ != 60 || a.Flag != 0x63 {
+		t.Errorf("QName, RName, Pos, MapQ, Flag = %q, %q, %d, %d, %#x", a.QName, a.RName, a.Pos, a.MapQ, a.Flag)
+	}
+	if a.RNext != "=" || a.PNext != 200 || a.TLen != 150 || a.Cigar.String() != "2S3M" {
+		t.Errorf("RNext, PNext, TLen, Cigar = %q, %d, %d, %s", a.RNext, a.PNext, a.TLen, a.Cigar)
+	}
+
+	// Unmapped read without reference
+	rec = testRecord{refID: -1, pos: -1, name: "u", flag: 0x4, seq: "AC", nextID: -1, pnext: -1}
+	a, err = r.decode(rec.encode())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.RName != "*" || a.RNext != "*" || a.Pos != 0 || a.PNext != 0 || a.Cigar.String() != "*" {
+		t.Errorf("RName, RNext, Pos, PNext, Cigar = %q, %q, %d, %d, %s", a.RName, a.RNext, a.Pos, a.PNext, a.Cigar)
+	}
+
+	// Invalid reference ID
+	rec.refID = 2
+	if _, err = r.decode(rec.encode()); err == nil {
+		t.Errorf("no error with an invalid reference ID")
+	}
+}
+
+func TestDecodeTags(t *testing.T) {
+	var tags bytes.Buffer
+	tags.Write([]byte("NMC\x02"))
+	tags.Write([]byte("XAAx"))
+	tags.Write([]byte("RGZgrp1\x00"))
+	tags.Write([]byte("ASs\xfe\xff"))
+	tags.Write([]byte("XFf"))
+	binary.Write(&tags, binary.LittleEndian, math.Float32bits(1.5))
+	tags.Write(arrayTag("ZC", 'c', int8(-1), int8(2), int8(-3)))
+	tags.Write(arrayTag("ZS", 'S', uint16(65535), uint16(1)))
+	tags.Write(arrayTag("ZF", 'f', float32(0.25), float32(-2)))
+	tags.Write(arrayTag("ZE", 'I'))
+
+	r := testReader()
+	rec := testRecord{refID: 0, pos: 0, name: "t", cigar: []uint32{cigarOp(1, 'M')}, seq: "A", nextID: -1, pnext: -1, tags: tags.Bytes()}
+	a, err := r.decode(rec.encode())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []sam.Tag{
+		{Name: "NM", Type: 'i', Value: 2},
+		{Name: "XA", Type: 'A', Value: "x"},
+		{Name: "RG", Type: 'Z', Value: "grp1"},
+		{Name: "AS", Type: 'i', Value: -2},
+		{Name: "XF", Type: 'f', Value: 1.5},
+		{Name: "ZC", Type: 'B', SubType: 'c', Value: []int{-1, 2, -3}},
+		{Name: "ZS", Type: 'B', SubType: 'S', Value: []int{65535, 1}},
+		{Name: "ZF", Type: 'B', SubType: 'f', Value: []float64{0.25, -2}},
+		{Name: "ZE", Type: 'B', SubType: 'I', Value: []int{}},
+	}
+	if !reflect.DeepEqual(a.Tags, want) {
+		t.Errorf("Tags =\n%v\nwant\n%v", a.Tags, want)
+	}
+
+	// Truncated array
+	rec.tags = arrayTag("ZC", 'i', int32(1), int32(2))[:12]
+	if _, err = r.decode(rec.encode()); err == nil {
+		t.Errorf("no error with a truncated array")
+	}
+}
+
+func TestDecodeLongCigar(t *testing.T) {
+	seq := strings.Repeat("ACGT", 5)
+	ops := []interface{}{cigarOp(5, 'S'), cigarOp(10, 'M'), cigarOp(2, 'I'), cigarOp(3, 'M'), cigarOp(100, 'N')}
+	var tags bytes.Buffer
+	tags.Write([]byte("NMC\x01"))
+	tags.Write(arrayTag("CG", 'I', ops...))
+
+	// Placeholder CIGAR: <lseq>S<reference length>N
+	r := testReader()
+	rec := testRecord{
+		refID: 0, pos: 0, name: "long",
+		cigar:  []uint32{cigarOp(len(seq), 'S'), cigarOp(113, 'N')},
+		seq:    seq,
+		nextID: -1, pnext: -1, tags: tags.Bytes(),
+	}
+	a, err := r.decode(rec.encode())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Cigar.String() != "5S10M2I3M100N" {
+		t.Errorf("Cigar = %s, want 5S10M2I3M100N", a.Cigar)
+	}
+	if len(a.Tags) != 1 || a.Tags[0].Name != "NM" {
+		t.Errorf("Tags = %v, the CG tag must be removed", a.Tags)
+	}
+
+	// A true soft-clip/skip CIGAR without CG tag is kept
+	rec.tags = nil
+	a, err = r.decode(rec.encode())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Cigar.String() != "20S113N" {
+		t.Errorf("Cigar = %s, want 20S113N", a.Cigar)
+	}
+}
+
+func TestReadBam(t *testing.T) {
+	le := binary.LittleEndian
+	// The text header differs from the binary dictionary
+	text := "@HD\tVN:1.6\tSO:unsorted\n@SQ\tSN:chr1\tLN:999\n"
+	var raw bytes.Buffer
+	raw.WriteString(Magic)
+	binary.Write(&raw, le, int32(len(text)))
+	raw.WriteString(text)
+	binary.Write(&raw, le, int32(2))
+	for _, ref := range []sam.Reference{{Name: "chr1", Length: 1000}, {Name: "chr2", Length: 500}} {
+		binary.Write(&raw, le, int32(len(ref.Name)+1))
+		raw.WriteString(ref.Name)
+		raw.WriteByte(0)
+		binary.Write(&raw, le, int32(ref.Length))
+	}
+	records := []testRecord{
+		{refID: 0, pos: 0, name: "r1", cigar: []uint32{cigarOp(4, 'M')}, seq: "ACGT", qual: []byte{1, 2, 3, 4}, nextID: -1, pnext: -1},
+		{refID: 0, pos: 5, name: "r1s", flag: sam.FlagSecondary, cigar: []uint32{cigarOp(4, 'M')}, nextID: -1, pnext: -1},
+		{refID: -1, pos: -1, name: "", flag: sam.FlagUnmapped, seq: "GG", qual: []byte{5, 5}, nextID: -1, pnext: -1},
+		{refID: 1, pos: 10, name: "r2", flag: sam.FlagReverse, cigar: []uint32{cigarOp(3, 'M')}, seq: "AAC", qual: []byte{10, 20, 30}, nextID: -1, pnext: -1},
+	}
+	for _, rec := range records {
+		data := rec.encode()
+		binary.Write(&raw, le, int32(len(data)))
+		raw.Write(data)
+	}
+
+	var file bytes.Buffer
+	w := bgzf.NewWriter(&file)
+	w.Write(raw.Bytes())
+	w.Close()
+
+	r := NewReader(&file)
+	h, err := r.Header()
+	if err != nil {
+		t.Fatal(err)
+	}
+	refs := []sam.Reference{{Name: "chr1", Length: 1000}, {Name: "chr2", Length: 500}}
+	if h.Version != "1.6" || !reflect.DeepEqual(h.References, refs) || len(h.Lines) != 2 {
+		t.Errorf("Version, References = %q, %v", h.Version, h.References)
+	}
+
+	// Secondary alignments are skipped, reverse reads are reverse-complemented
+	// and a nameless record does not end the stream
+	want := []struct{ id, seq, qual string }{
+		{"r1", "ACGT", "\"#$%"},
+		{"", "GG", "&&"},
+		{"r2", "GTT", "?5+"},
+	}
+	for _, w := range want {
+		s, err := r.Read()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s.Id != w.id || string(s.Sequence) != w.seq || string(s.Quality.StrScore) != w.qual {
+			t.Errorf("Read = %s %s %s, want %s %s %s", s.Id, s.Sequence, s.Quality.StrScore, w.id, w.seq, w.qual)
+		}
+	}
+	s, err := r.Read()
+	if err != nil || s.Length() != 0 || !r.IsEOF() {
+		t.Errorf("end of file: %v, %d, %v", err, s.Length(), r.IsEOF())
+	}
+}
@@ -0,0 +1,280 @@
+package seqio
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/hdevillers/go-seq/seq"
+	"github.com/hdevillers/go-seq/seqio/seqitf"
+)
+
+/*
+	Parallel reader: the (decompressed) input is split into chunks aligned
+	on record boundaries, the chunks are parsed by several goroutines and
+	the resulting batches of sequences are delivered in the input order.
+	NOTE: FASTQ records must be made of four lines (wrapped records are
+	rejected with an error).
+*/
+
+// A chunk of raw data and the channel of its parsing result
+type batchJob struct {
+	data   []byte
+	result chan batchResult
+}
+
+type batchResult struct {
+	seqs  []seq.Seq
+	warns []error
+	lines int
+	err   error
+}
+
+// Batch reader structure
+type BatchReader struct {
+	fcloser seqitf.FileCloser
+	format  string
+	order   chan chan batchResult
+	quit    chan struct{}
+	once    sync.Once
+	batch   []seq.Seq
+	warns   []error
+	records int
+	lines   int
+	err     error
+}
+
+// Open a sequence file for parallel parsing
+func OpenBatch(file string, format string, opts ...Option) (*BatchReader, error) {
+	f, err := openFile(file)
+	if err != nil {
+		return nil, err
+	}
+	return newBatchReader(f, f, format, opts)
+}
+
+// Create a new batch reader from an io.Reader
+// NOTE: r is not closed by Close
+func NewBatchReader(r io.Reader, format string, opts ...Option) (*BatchReader, error) {
+	return newBatchReader(r, closeFunc(func() {}), format, opts)
+}
+
+func newBatchReader(in io.Reader, f seqitf.FileCloser, format string, opts []Option) (*BatchReader, error) {
+	o := newOptions(AutoCompression, opts)
+	in, fc, sf, err := prepareInput(in, f, format, o.Compression)
+	if err != nil {
+		return nil, err
+	}
+	format = sf.Name
+
+	var boundary func([]byte) int
+	switch format {
+	case "fastq", "fastnq":
+		// Chunks are split every four lines, wrapped records are rejected
+		boundary = fastqBoundary
+		o.Strict = true
+	case "fasta":
+		boundary = fastaBoundary
+	default:
+		fc.Close()
+		return nil, fmt.Errorf("[SEQIO BATCH READER]: %w (%s).", ErrUnsupportedFormat, format)
+	}
+
+	b := BatchReader{
+		fcloser: fc,
+		format:  format,
+		order:   make(chan chan batchResult, 2*o.Threads),
+		quit:    make(chan struct{}),
+	}
+
+	// Parsing goroutines
+	jobs := make(chan batchJob, o.Threads)
+	for i := 0; i < o.Threads; i++ {
+		go func() {
+			for job := range jobs {
+				job.result <- parseBatch(job.data, sf, o)
+			}
+		}()
+	}
+
+	// Chunking goroutine
+	go b.split(in, boundary, o.BatchSize, jobs)
+
+	return &b, nil
+}
+
+// End of the last complete FASTQ record (4 lines per record)
+func fastqBoundary(data []byte) int {
+	end := 0
+	n := 0
+	for i := 0; i < len(data); {
+		j := bytes.IndexByte(data[i:], '\n')
+		if j < 0 {
+			break
+		}
+		i += j + 1
+		n++
+		if n%4 == 0 {
+			end = i
+		}
+	}
+	return end
+}
+
+// Start of the last FASTA record
+func fastaBoundary(data []byte) int {
+	return bytes.LastIndex(data, []byte("\n>")) + 1
+}
+
+// Split the input in chunks and send them to the parsers (in order)
+func (b *BatchReader) split(in io.Reader, boundary func([]byte) int, size int, jobs chan batchJob) {
+	defer close(b.order)
+	defer close(jobs)
+
+	var rest []byte
+	for {
+		// Fill a new chunk after the remaining data of the previous one
+		// NOTE: the chunk size is doubled while a record does not fit in
+		// (large chromosomes)
+		grow := size
+		if len(rest) > grow {
+			grow = len(rest)
+		}
+		chunk := make([]byte, len(rest), len(rest)+grow)
+		copy(chunk, rest)
+		n, err := io.ReadFull(in, chunk[len(rest):cap(chunk)])
+		chunk = chunk[:len(rest)+n]
+		eof := err == io.EOF || err == io.ErrUnexpectedEOF
+		if err != nil && !eof {
+			b.send(batchJob{result: make(chan batchResult, 1)}, jobs, err)
+			return
+		}
+
+		end := len(chunk)
+		if !eof {
+			end = boundary(chunk)
+		}
+		rest = chunk[end:]
+		if end > 0 {
+			if !b.send(batchJob{data: chunk[:end], result: make(chan batchResult, 1)}, jobs, nil) {
+				return
+			}
+		}
+		if eof {
+			return
+		}
+	}
+}
+
+// Queue a job (or directly an error), return false if the reader is closed
+func (b *BatchReader) send(job batchJob, jobs chan batchJob, err error) bool {
+	select {
+	case b.order <- job.result:
+	case <-b.quit:
+		return false
+	}
+	if err != nil {
+		job.result <- batchResult{err: err}
+		return false
+	}
+	jobs <- job
+	return true
+}
+
+// Parse a chunk of records
+func parseBatch(data []byte, sf *Format, o Options) batchResult {
+	var res batchResult
+	res.lines = bytes.Count(data, []byte{'\n'})
+
+	sreader, err := newSeqReader(sf, bytes.NewReader(data), o)
+	if err != nil {
+		res.err = err
+		return res
+	}
+
+	for !sreader.IsEOF() {
+		s, err := sreader.Read()
+		if err != nil {
+			res.err = err
+			return res
+		}
+		if s.Length() == 0 {
+			break
+		}
+		if w, ok := sreader.(seqitf.SeqWarner); ok && w.Warning() != nil {
+			res.warns = append(res.warns, w.Warning())
+		}
+		res.seqs = append(res.seqs, s)
+	}
+	return res
+}
+
+// Get the next batch of sequences
+func (b *BatchReader) Next() bool {
+	if b.err != nil {
+		return false
+	}
+	result, ok := <-b.order
+	if !ok {
+		return false
+	}
+	res := <-result
+	if res.err != nil {
+		b.locate(res.err)
+		b.err = res.err
+		b.batch = nil
+		b.warns = nil
+		return false
+	}
+	for _, w := range res.warns {
+		b.locate(w)
+	}
+	b.batch = res.seqs
+	b.warns = res.warns
+	b.records += len(res.seqs)
+	b.lines += res.lines
+	return true
+}
+
+// Locate an error of the current batch in the whole input
+func (b *BatchReader) locate(err error) {
+	var pe *ParseError
+	if errors.As(err, &pe) {
+		pe.Line += b.lines
+		pe.Record += b.records
+	}
+}
+
+// Get the current batch of sequences
+func (b *BatchReader) Batch() []seq.Seq {
+	return b.batch
+}
+
+// Get the warnings raised while parsing the current batch (nil if none)
+// NOTE: only the fastq reader raises warnings (quality issues), use the
+// Record field of the ParseError to find the concerned sequence
+func (b *BatchReader) Warnings() []error {
+	return b.warns
+}
+
+// Get the format of the input
+func (b *BatchReader) Format() string {
+	return b.format
+}
+
+// Get the last error
+func (b *BatchReader) Err() error {
+	return b.err
+}
+
+// Stop the parsing goroutines and close the input
+func (b *BatchReader) Close() error {
+	var err error
+	b.once.Do(func() {
+		close(b.quit)
+		err = b.fcloser.Close()
+	})
+	return err
+}
@@ -0,0 +1,178 @@
+package bgzf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+
+	"github.com/klauspost/compress/flate"
+)
+
+/*
+	BGZF (blocked gzip) is a series of gzip members (blocks) of at most
+	64 KiB of uncompressed data. Each block header contains a "BC" extra
+	sub-field giving the compressed size of the block, so that a position
+	in the uncompressed stream can be addressed by a virtual offset:
+	(compressed offset of the block << 16) | offset within the block.
+*/
+
+const (
+	BlockHeaderLength int = 18
+	BlockFooterLength int = 8
+	MaxBlockSize      int = 65536
+)
+
+// Virtual file offset
+type Offset uint64
+
+// Build a virtual offset
+func NewOffset(block int64, within int) Offset {
+	return Offset(uint64(block)<<16 | uint64(within))
+}
+
+// Compressed offset of the block
+func (o Offset) Block() int64 {
+	return int64(o >> 16)
+}
+
+// Offset within the uncompressed block
+func (o Offset) Within() int {
+	return int(o & 0xffff)
+}
+
+// BGZF reader struct
+type Reader struct {
+	r      io.Reader
+	head   [BlockHeaderLength]byte
+	cdata  []byte
+	block  []byte
+	pos    int
+	offset int64
+	next   int64
+	inflat io.ReadCloser
+	eof    bool
+}
+
+// Generate a new reader
+func NewReader(r io.Reader) *Reader {
+	return &Reader{
+		r:     r,
+		cdata: make([]byte, MaxBlockSize),
+		block: make([]byte, 0, MaxBlockSize),
+		eof:   false,
+	}
+}
+
+// Read and decompress the next block
+func (r *Reader) readBlock() error {
+	// Block header
+	_, err := io.ReadFull(r.r, r.head[:])
+	if err == io.EOF {
+		r.eof = true
+		return io.EOF
+	}
+	if err != nil {
+		return err
+	}
+	h := r.head
+	if h[0] != 31 || h[1] != 139 || h[2] != 8 || h[3]&4 == 0 {
+		return errors.New("[BGZF READER]: Invalid block header.")
+	}
+	xlen := int(binary.LittleEndian.Uint16(h[10:12]))
+	if xlen < 6 || h[12] != 'B' || h[13] != 'C' || binary.LittleEndian.Uint16(h[14:16]) != 2 {
+		return errors.New("[BGZF READER]: Missing BC extra field (not a BGZF file).")
+	}
+	bsize := int(binary.LittleEndian.Uint16(h[16:18])) + 1
+
+	// Remaining extra fields, compressed data and footer
+	rest := bsize - BlockHeaderLength
+	if rest < BlockFooterLength+xlen-6 {
+		return errors.New("[BGZF READER]: Invalid block size.")
+	}
+	cdata := r.cdata[:rest]
+	_, err = io.ReadFull(r.r, cdata)
+	if err != nil {
+		return errors.New("[BGZF READER]: Truncated block.")
+	}
+	footer := cdata[len(cdata)-BlockFooterLength:]
+	cdata = cdata[xlen-6 : len(cdata)-BlockFooterLength]
+	sum := binary.LittleEndian.Uint32(footer[0:4])
+	isize := int(binary.LittleEndian.Uint32(footer[4:8]))
+	if isize > MaxBlockSize {
+		return errors.New("[BGZF READER]: Invalid uncompressed block size.")
+	}
+
+	// Inflate the data
+	if r.inflat == nil {
+		r.inflat = flate.NewReader(bytes.NewReader(cdata))
+	} else {
+		err = r.inflat.(flate.Resetter).Reset(bytes.NewReader(cdata), nil)
+		if err != nil {
+			return err
+		}
+	}
+	r.block = r.block[:isize]
+	_, err = io.ReadFull(r.inflat, r.block)
+	if err != nil {
+		return errors.New("[BGZF READER]: Corrupted block data.")
+	}
+	if crc32.ChecksumIEEE(r.block) != sum {
+		return errors.New("[BGZF READER]: Block checksum mismatch.")
+	}
+
+	r.offset = r.next
+	r.next += int64(bsize)
+	r.pos = 0
+	return nil
+}
+
+// Read decompressed data
+func (r *Reader) Read(p []byte) (int, error) {
+	for r.pos >= len(r.block) {
+		if r.eof {
+			return 0, io.EOF
+		}
+		err := r.readBlock()
+		if err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, r.block[r.pos:])
+	r.pos += n
+	return n, nil
+}
+
+// Get the virtual offset of the next byte to read
+func (r *Reader) Offset() Offset {
+	if r.pos >= len(r.block) {
+		return NewOffset(r.next, 0)
+	}
+	return NewOffset(r.offset, r.pos)
+}
+
+// Move to a virtual offset (the underlying reader must be an io.Seeker)
+func (r *Reader) Seek(o Offset) error {
+	s, ok := r.r.(io.Seeker)
+	if !ok {
+		return errors.New("[BGZF READER]: The input does not support seeking.")
+	}
+	_, err := s.Seek(o.Block(), io.SeekStart)
+	if err != nil {
+		return err
+	}
+	r.eof = false
+	r.next = o.Block()
+	r.block = r.block[:0]
+	r.pos = 0
+	err = r.readBlock()
+	if err != nil && err != io.EOF {
+		return err
+	}
+	if o.Within() > len(r.block) {
+		return errors.New("[BGZF READER]: Invalid virtual offset.")
+	}
+	r.pos = o.Within()
+	return nil
+}
@@ -0,0 +1,194 @@
+package bgzf
+
+import (
+	"bytes"
+	"encoding/hex"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// bgzip layout of fixtureData: 3 blocks of at most 0xff00 bytes (zlib raw
+// deflate, level 6) and the EOF block
+var fixture, _ = hex.DecodeString(
+	"1f8b08040000000000ff060042430200fb00edcbb10900200c00b0dd6f7c4028" +
+		"1dfa403fe9ffe00f8e922953ceec1559dd95f1a22ccbb22ccbb22ccbb22ccbb2" +
+		"2ccbb22ccbb22ccbb22ccbb22ccbb22ccbb22ccbb22ccbb22ccbb22ccbb22ccb" +
+		"b22ccbb22ccbb22ccbb22ccbb22ccbb22ccbb22ccbb22ccbb22ccbb22ccbb22c" +
+		"cbb22ccbb22ccbb22ccbb22ccbb22ccbb22ccbb22ccbb22ccbb22ccbb22ccbb2" +
+		"2ccbb22ccbb22ccbb22ccbb22ccbb22ccbb22ccbb22ccbb22ccbb22ccbb22ccb" +
+		"b22ccbb22ccbb22ccbb22ccbb22ccbb22ccbb22ccbb22ccbb22ccbb22ccbb22c" +
+		"cbb22ccbb22ccbb22ccbb22ccbb22ccbf22ff902506b99d200ff00001f8b0804" +
+		"0000000000ff060042430200f800edcb211100300c04303e57bd073550ff5aa6" +
+		"614503414149557aa6f3e4d92459966559966559966559966559966559966559" +
+		"9665599665599665599665599665599665599665599665599665599665599665" +
+		"5996655996655996655996655996655996655996655996655996655996655996" +
+		"6559966559966559966559966559966559966559966559966559966559966559" +
+		"9665599665599665599665599665599665599665599665599665599665599665" +
+		"5996655996655996655996655996655996655996655996655996655996655996" +
+		"655996655996655996e5aff3059764c71f00ff00001f8b08040000000000ff06" +
+		"00424302006900edcb310d00400804c1fe5d5d2830807f2d28f80401536db393" +
+		"ea99aee4d0779960188661188661188661188661188661188661188661188661" +
+		"1886611886611886611886611886611886611886e12f5ed913b1ace43d00001f" +
+		"8b08040000000000ff0600424302001b0003000000000000000000")
+
+// Uncompressed content of the fixture (146404 bytes)
+var fixtureData = func() []byte {
+	line := strings.Repeat("ACGTTGCA", 8)[:60] + "\n"
+	return []byte(">s1\n" + strings.Repeat(line, 2400))
+}()
+
+// Block offsets of the fixture
+var fixtureBlocks = []BlockOffset{{252, 65280}, {501, 130560}}
+
+func TestEOFBlock(t *testing.T) {
+	// EOF marker of the SAM/BAM specification
+	spec, _ := hex.DecodeString("1f8b08040000000000ff0600424302001b0003000000000000000000")
+	if !bytes.Equal(EOFBlock, spec) {
+		t.Fatalf("EOFBlock = %x", EOFBlock)
+	}
+	if !bytes.HasSuffix(fixture, EOFBlock) || !IsBgzf(fixture) || !IsBgzf(EOFBlock) {
+		t.Errorf("fixture is not a BGZF file with an EOF block")
+	}
+
+	// The EOF block contains no data
+	n, err := NewReader(bytes.NewReader(EOFBlock)).Read(make([]byte, 10))
+	if n != 0 || err != io.EOF {
+		t.Errorf("Read(EOFBlock) = %d, %v, want 0, EOF", n, err)
+	}
+
+	// Closing an empty writer only writes the EOF block
+	var out bytes.Buffer
+	w := NewWriter(&out)
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(out.Bytes(), EOFBlock) {
+		t.Errorf("empty file = %x", out.Bytes())
+	}
+}
+
+func TestReadBlocks(t *testing.T) {
+	r := NewReader(bytes.NewReader(fixture))
+	data, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, fixtureData) {
+		t.Fatalf("read %d bytes, want %d", len(data), len(fixtureData))
+	}
+
+	// Virtual offsets at the block boundaries
+	r = NewReader(bytes.NewReader(fixture))
+	buf := make([]byte, 100)
+	if _, err = io.ReadFull(r, buf); err != nil {
+		t.Fatal(err)
+	}
+	if o := r.Offset(); o != NewOffset(0, 100) {
+		t.Errorf("Offset = (%d, %d), want (0, 100)", o.Block(), o.Within())
+	}
+	if _, err = io.ReadFull(r, make([]byte, 65280-100+10)); err != nil {
+		t.Fatal(err)
+	}
+	if o := r.Offset(); o != NewOffset(252, 10) {
+		t.Errorf("Offset = (%d, %d), want (252, 10)", o.Block(), o.Within())
+	}
+
+	// Corrupted data
+	bad := append([]byte(nil), fixture...)
+	bad[100] ^= 0xff
+	if _, err = io.ReadAll(NewReader(bytes.NewReader(bad))); err == nil {
+		t.Errorf("no error with a corrupted block")
+	}
+	if _, err = io.ReadAll(NewReader(bytes.NewReader(fixture[:300]))); err == nil {
+		t.Errorf("no error with a truncated block")
+	}
+}
+
+func TestIndex(t *testing.T) {
+	idx, err := BuildIndex(bytes.NewReader(fixture))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(idx.Blocks, fixtureBlocks) {
+		t.Errorf("Blocks = %v, want %v", idx.Blocks, fixtureBlocks)
+	}
+
+	// .gzi layout: count, then (compressed, uncompressed) uint64 pairs
+	gzi, _ := hex.DecodeString("0200000000000000" +
+		"fc00000000000000" + "00ff000000000000" +
+		"f501000000000000" + "00fe010000000000")
+	var out bytes.Buffer
+	if err = idx.Write(&out); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(out.Bytes(), gzi) {
+		t.Errorf(".gzi = %x, want %x", out.Bytes(), gzi)
+	}
+	read, err := ReadIndex(bytes.NewReader(gzi))
+	if err != nil || !reflect.DeepEqual(read.Blocks, fixtureBlocks) {
+		t.Errorf("ReadIndex = %v, %v", read, err)
+	}
+	if _, err = ReadIndex(bytes.NewReader(gzi[:20])); err == nil {
+		t.Errorf("no error with a truncated index")
+	}
+
+	// Virtual offsets of uncompressed offsets
+	for _, tt := range []struct {
+		u     int64
+		block int64
+		in    int
+	}{{0, 0, 0}, {65279, 0, 65279}, {65280, 252, 0}, {130561, 501, 1}, {146403, 501, 15843}} {
+		if o := idx.Offset(tt.u); o.Block() != tt.block || o.Within() != tt.in {
+			t.Errorf("Offset(%d) = (%d, %d), want (%d, %d)", tt.u, o.Block(), o.Within(), tt.block, tt.in)
+		}
+	}
+}
+
+func TestWriterIndex(t *testing.T) {
+	var out bytes.Buffer
+	w := NewWriter(&out)
+	if _, err := w.Write(fixtureData); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Same blocks as bgzip (the compressed sizes may differ)
+	idx, err := BuildIndex(bytes.NewReader(out.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(w.Index().Blocks, idx.Blocks) {
+		t.Errorf("writer index = %v, want %v", w.Index().Blocks, idx.Blocks)
+	}
+	if len(idx.Blocks) != 2 || idx.Blocks[0].Uncompressed != 65280 || idx.Blocks[1].Uncompressed != 130560 {
+		t.Errorf("Blocks = %v", idx.Blocks)
+	}
+	data, err := io.ReadAll(NewReader(bytes.NewReader(out.Bytes())))
+	if err != nil || !bytes.Equal(data, fixtureData) {
+		t.Errorf("round trip failed (%v)", err)
+	}
+}
+
+func TestReaderAt(t *testing.T) {
+	idx := &Index{Blocks: fixtureBlocks}
+	// Index with the terminal entry added by htslib when indexing a file
+	// opened for reading
+	ext := &Index{Blocks: append(append([]BlockOffset(nil), fixtureBlocks...), BlockOffset{607, 146404})}
+	for _, ix := range []*Index{idx, ext} {
+		ra := NewReaderAt(bytes.NewReader(fixture), ix)
+		for _, off := range []int64{0, 65270, 65280, 130000, 146400} {
+			p := make([]byte, 4)
+			if off+20 <= int64(len(fixtureData)) {
+				p = make([]byte, 20)
+			}
+			n, err := ra.ReadAt(p, off)
+			if err != nil || !bytes.Equal(p[:n], fixtureData[off:off+int64(len(p))]) {
+				t.Errorf("ReadAt(%d) = %q, %v", off, p[:n], err)
+			}
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package bgzf
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"sort"
+)
+
+/*
+	.gzi index (htslib): the number of entries followed by the
+	(compressed, uncompressed) offsets of each block start, the first
+	block (0, 0) being implicit. All values are little-endian uint64.
+*/
+
+// Start offsets of a block
+type BlockOffset struct {
+	Compressed   int64
+	Uncompressed int64
+}
+
+// BGZF block index
+type Index struct {
+	Blocks []BlockOffset
+}
+
+// Build the index by scanning all the blocks of a BGZF stream
+func BuildIndex(r io.Reader) (*Index, error) {
+	br := NewReader(r)
+	idx := Index{}
+	var uoffset int64
+	for {
+		err := br.readBlock()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if br.offset != 0 && len(br.block) > 0 {
+			idx.Blocks = append(idx.Blocks, BlockOffset{Compressed: br.offset, Uncompressed: uoffset})
+		}
+		uoffset += int64(len(br.block))
+	}
+	return &idx, nil
+}
+
+// Read a .gzi file
+func ReadIndex(r io.Reader) (*Index, error) {
+	var n uint64
+	err := binary.Read(r, binary.LittleEndian, &n)
+	if err != nil {
+		return nil, errors.New("[BGZF INDEX]: Truncated index.")
+	}
+	idx := Index{}
+	for i := uint64(0); i < n; i++ {
+		var v [2]uint64
+		err = binary.Read(r, binary.LittleEndian, &v)
+		if err != nil {
+			return nil, errors.New("[BGZF INDEX]: Truncated index.")
+		}
+		idx.Blocks = append(idx.Blocks, BlockOffset{Compressed: int64(v[0]), Uncompressed: int64(v[1])})
+	}
+	return &idx, nil
+}
+
+// Write the index in .gzi format
+func (idx *Index) Write(w io.Writer) error {
+	err := binary.Write(w, binary.LittleEndian, uint64(len(idx.Blocks)))
+	if err != nil {
+		return err
+	}
+	for _, b := range idx.Blocks {
+		err = binary.Write(w, binary.LittleEndian, [2]uint64{uint64(b.Compressed), uint64(b.Uncompressed)})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Virtual offset of an uncompressed offset
+func (idx *Index) Offset(uoffset int64) Offset {
+	// Last block starting before (or at) the offset
+	i := sort.Search(len(idx.Blocks), func(i int) bool {
+		return idx.Blocks[i].Uncompressed > uoffset
+	})
+	if i == 0 {
+		return NewOffset(0, int(uoffset))
+	}
+	b := idx.Blocks[i-1]
+	return NewOffset(b.Compressed, int(uoffset-b.Uncompressed))
+}
+
+// Random access to the uncompressed data of a BGZF file
+type ReaderAt struct {
+	r   *Reader
+	idx *Index
+}
+
+// Generate a new random access reader from a seekable BGZF input
+func NewReaderAt(rs io.ReadSeeker, idx *Index) *ReaderAt {
+	return &ReaderAt{
+		r:   NewReader(rs),
+		idx: idx,
+	}
+}
+
+// Read uncompressed data from an uncompressed offset
+// NOTE: calls must not be concurrent
+func (ra *ReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	err := ra.r.Seek(ra.idx.Offset(off))
+	if err != nil {
+		return 0, err
+	}
+	return io.ReadFull(ra.r, p)
+}
+
+// Close the underlying input if possible
+func (ra *ReaderAt) Close() error {
+	if c, ok := ra.r.r.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// Check if a file header is a BGZF block header
+func IsBgzf(head []byte) bool {
+	return len(head) >= 16 && head[0] == 31 && head[1] == 139 && head[2] == 8 &&
+		head[3]&4 != 0 && head[12] == 'B' && head[13] == 'C'
+}
@@ -0,0 +1,146 @@
+package bgzf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"io"
+
+	"github.com/klauspost/compress/flate"
+)
+
+const (
+	// Uncompressed data per block (as in htslib, so that a block
+	// compressed in the worst case still fits in 64 KiB)
+	BlockDataSize int = 0xff00
+	DefaultLevel  int = flate.DefaultCompression
+)
+
+// Empty block marking the end of a BGZF file
+var EOFBlock = []byte{
+	0x1f, 0x8b, 0x08, 0x04, 0x00, 0x00, 0x00, 0x00, 0x00, 0xff, 0x06, 0x00,
+	0x42, 0x43, 0x02, 0x00, 0x1b, 0x00, 0x03, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0x00, 0x00, 0x00, 0x00,
+}
+
+// BGZF writer struct
+type Writer struct {
+	w       io.Writer
+	level   int
+	data    []byte
+	cbuf    bytes.Buffer
+	deflate *flate.Writer
+	offset  int64
+	uoffset int64
+	index   Index
+	closed  bool
+}
+
+// Generate a new writer (with an optional compression level)
+func NewWriter(w io.Writer, level ...int) *Writer {
+	l := DefaultLevel
+	if len(level) > 0 {
+		l = level[0]
+	}
+	return &Writer{
+		w:     w,
+		level: l,
+		data:  make([]byte, 0, BlockDataSize),
+	}
+}
+
+// Compress and write out a block
+func (w *Writer) writeBlock(data []byte) error {
+	var err error
+	w.cbuf.Reset()
+	if w.deflate == nil {
+		w.deflate, err = flate.NewWriter(&w.cbuf, w.level)
+		if err != nil {
+			return err
+		}
+	} else {
+		w.deflate.Reset(&w.cbuf)
+	}
+	_, err = w.deflate.Write(data)
+	if err != nil {
+		return err
+	}
+	err = w.deflate.Close()
+	if err != nil {
+		return err
+	}
+
+	// Header, compressed data and footer
+	var head [BlockHeaderLength]byte
+	copy(head[:], []byte{31, 139, 8, 4, 0, 0, 0, 0, 0, 0xff, 6, 0, 'B', 'C', 2, 0})
+	bsize := BlockHeaderLength + w.cbuf.Len() + BlockFooterLength
+	binary.LittleEndian.PutUint16(head[16:18], uint16(bsize-1))
+	var foot [BlockFooterLength]byte
+	binary.LittleEndian.PutUint32(foot[0:4], crc32.ChecksumIEEE(data))
+	binary.LittleEndian.PutUint32(foot[4:8], uint32(len(data)))
+
+	for _, b := range [][]byte{head[:], w.cbuf.Bytes(), foot[:]} {
+		_, err = w.w.Write(b)
+		if err != nil {
+			return err
+		}
+	}
+
+	w.offset += int64(bsize)
+	w.uoffset += int64(len(data))
+	w.index.Blocks = append(w.index.Blocks, BlockOffset{Compressed: w.offset, Uncompressed: w.uoffset})
+	return nil
+}
+
+// Write uncompressed data
+func (w *Writer) Write(p []byte) (int, error) {
+	n := 0
+	for len(p) > 0 {
+		k := copy(w.data[len(w.data):cap(w.data)], p)
+		w.data = w.data[:len(w.data)+k]
+		p = p[k:]
+		n += k
+		if len(w.data) == cap(w.data) {
+			err := w.Flush()
+			if err != nil {
+				return n, err
+			}
+		}
+	}
+	return n, nil
+}
+
+// Write out the pending data as a block
+func (w *Writer) Flush() error {
+	if len(w.data) == 0 {
+		return nil
+	}
+	err := w.writeBlock(w.data)
+	w.data = w.data[:0]
+	return err
+}
+
+// Flush the pending data and add the EOF marker block
+// NOTE: the underlying writer is not closed
+func (w *Writer) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	err := w.Flush()
+	if err != nil {
+		return err
+	}
+	_, err = w.w.Write(EOFBlock)
+	return err
+}
+
+// Get the index of the written blocks
+func (w *Writer) Index() *Index {
+	idx := Index{}
+	// The last block start is the end of file, not a block
+	if len(w.index.Blocks) > 1 {
+		idx.Blocks = append(idx.Blocks, w.index.Blocks[:len(w.index.Blocks)-1]...)
+	}
+	return &idx
+}
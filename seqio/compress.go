@@ -0,0 +1,128 @@
+package seqio
+
+import (
+	"bufio"
+	"compress/bzip2"
+	"errors"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+	gzip "github.com/klauspost/pgzip"
+	"github.com/ulikunitz/xz"
+
+	"github.com/hdevillers/go-seq/seqio/bgzf"
+	"github.com/hdevillers/go-seq/seqio/seqitf"
+)
+
+// Close several handles in order (e.g. compressor then file)
+type multiCloser []seqitf.FileCloser
+
+func (m multiCloser) Close() error {
+	var err error
+	for _, c := range m {
+		cerr := c.Close()
+		if err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// Close function adapter (for closers that do not return errors)
+type closeFunc func()
+
+func (f closeFunc) Close() error {
+	f()
+	return nil
+}
+
+// Select a compression from a file extension
+func CompressionFromExtension(file string) Compression {
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".gz", ".gzip":
+		return Gzip
+	case ".bgz", ".bgzf":
+		return Bgzf
+	case ".bz2", ".bzip2":
+		return Bzip2
+	case ".xz":
+		return Xz
+	case ".zst", ".zstd":
+		return Zstd
+	}
+	return NoCompression
+}
+
+// Check if a compression is supported in write mode
+func isWritable(c Compression) bool {
+	switch c {
+	case NoCompression, Gzip, Bgzf, Xz, Zstd:
+		return true
+	}
+	return false
+}
+
+// Set up the decompression of an input, fc closes the decompressor and f
+func newDecompressor(c Compression, in io.Reader, f seqitf.FileCloser) (io.Reader, seqitf.FileCloser, error) {
+	switch c {
+	case NoCompression:
+		// No de-compression needed
+		return in, f, nil
+	case Gzip:
+		fgz, err := gzip.NewReader(in)
+		if err != nil {
+			return nil, nil, err
+		}
+		return fgz, multiCloser{fgz, f}, nil
+	case Bgzf:
+		// De-compression block by block
+		return bgzf.NewReader(in), f, nil
+	case Bzip2:
+		return bzip2.NewReader(in), f, nil
+	case Xz:
+		fxz, err := xz.NewReader(in)
+		if err != nil {
+			return nil, nil, err
+		}
+		return fxz, f, nil
+	case Zstd:
+		fzst, err := zstd.NewReader(in)
+		if err != nil {
+			return nil, nil, err
+		}
+		return fzst, multiCloser{closeFunc(fzst.Close), f}, nil
+	}
+	return nil, nil, errors.New("[SEQIO READER]: Unsupported compression (" + c.String() + ").")
+}
+
+// Set up the compression of an output, fc closes the compressor and f
+func newCompressor(c Compression, out io.Writer, f seqitf.FileCloser) (seqitf.FileWriter, seqitf.FileCloser, error) {
+	switch c {
+	case NoCompression:
+		// No compression needed
+		return bufio.NewWriter(out), f, nil
+	case Gzip:
+		fgz := gzip.NewWriter(out)
+		return fgz, multiCloser{fgz, f}, nil
+	case Bgzf:
+		// Compression block by block
+		fbgz := bgzf.NewWriter(out)
+		return fbgz, multiCloser{fbgz, f}, nil
+	case Xz:
+		fxz, err := xz.NewWriter(out)
+		if err != nil {
+			return nil, nil, err
+		}
+		// NOTE: xz streams cannot be flushed, data are buffered until closing
+		return bufio.NewWriter(fxz), multiCloser{fxz, f}, nil
+	case Zstd:
+		fzst, err := zstd.NewWriter(out)
+		if err != nil {
+			return nil, nil, err
+		}
+		return fzst, multiCloser{fzst, f}, nil
+	}
+	return nil, nil, errors.New("[SEQIO WRITER]: Unsupported compression (" + c.String() + ").")
+}
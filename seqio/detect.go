@@ -0,0 +1,97 @@
+package seqio
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/hdevillers/go-seq/seqio/bgzf"
+	"github.com/hdevillers/go-seq/seqio/seqitf"
+)
+
+const (
+	// Bytes read ahead to detect the compression and the format
+	// NOTE: a whole BGZF block must fit to detect BAM files
+	sniffSize int = 1 << 17
+)
+
+// Compression magic numbers
+var (
+	magicGzip  = []byte{0x1f, 0x8b}
+	magicBzip2 = []byte("BZh")
+	magicXz    = []byte{0xfd, '7', 'z', 'X', 'Z', 0x00}
+	magicZstd  = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// Detect the compression from the first bytes of a file
+func sniffCompression(head []byte) Compression {
+	switch {
+	case bgzf.IsBgzf(head):
+		return Bgzf
+	case bytes.HasPrefix(head, magicGzip):
+		return Gzip
+	case bytes.HasPrefix(head, magicBzip2):
+		return Bzip2
+	case bytes.HasPrefix(head, magicXz):
+		return Xz
+	case bytes.HasPrefix(head, magicZstd):
+		return Zstd
+	}
+	return NoCompression
+}
+
+// Detect the compression and the format if required and set up the
+// decompression of an input (raw formats such as BAM are not decompressed)
+// NOTE: f is closed in case of error
+func prepareInput(in io.Reader, f seqitf.FileCloser, format string, c Compression) (io.Reader, seqitf.FileCloser, *Format, error) {
+	var sf *Format
+	if format != "auto" {
+		var ok bool
+		sf, ok = LookupFormat(format)
+		if !ok || sf.NewReader == nil {
+			f.Close()
+			return nil, nil, nil, fmt.Errorf("[SEQIO READER]: %w (%s).", ErrUnsupportedFormat, format)
+		}
+	}
+
+	// Detect the compression (and the raw formats) from the first bytes
+	if c == AutoCompression || sf == nil {
+		br := bufio.NewReaderSize(in, sniffSize)
+		head, _ := br.Peek(sniffSize)
+		c = sniffCompression(head)
+		if sf == nil {
+			sf = sniffFormat(head, true)
+		}
+		in = br
+	}
+	if sf != nil && sf.Raw {
+		return in, f, sf, nil
+	}
+
+	// Inti. the decompressor
+	in, fc, err := newDecompressor(c, in, f)
+	if err != nil {
+		f.Close()
+		return nil, nil, nil, err
+	}
+
+	// Detect the format from the first (decompressed) bytes
+	if sf == nil {
+		br := bufio.NewReaderSize(in, sniffSize)
+		head, _ := br.Peek(sniffSize)
+		if len(head) == 0 {
+			// NOTE: an empty input has no sequence whatever its format
+			sf, _ = LookupFormat("fasta")
+		} else {
+			sf = sniffFormat(head, false)
+		}
+		if sf == nil || sf.NewReader == nil {
+			fc.Close()
+			return nil, nil, nil, errors.New("[SEQIO READER]: Unable to detect the input format.")
+		}
+		in = br
+	}
+	return in, fc, sf, nil
+}
@@ -0,0 +1,523 @@
+package embl
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"hash/crc64"
+	"math"
+	"strings"
+
+	"github.com/hdevillers/go-seq/feature"
+	"github.com/hdevillers/go-seq/seq"
+	"github.com/hdevillers/go-seq/seqio/seqitf"
+)
+
+/*
+	EMBL and UniProtKB/Swiss-Prot flat files share the same line
+	structure: a two-letter line code, three spaces and the data.
+	Proteins (UniProt) are detected from the "AA" unit of the ID line.
+*/
+
+const (
+	EndOfRecord string = "//"
+	DataIndent  int    = 5
+	LineLength  int    = 60
+	BlockLength int    = 10
+)
+
+const (
+	defaultMolType   = "genomic DNA"
+	defaultTopology  = "linear"
+	defaultDataClass = "STD"
+	defaultDivision  = "UNC"
+	defaultStatus    = "Unreviewed"
+	waterMass        = 18.01524
+)
+
+// Average residue masses (ExPASy values, as used for UniProt MW)
+var aaMass = map[byte]float64{
+	'A': 71.0788, 'C': 103.1388, 'D': 115.0886, 'E': 129.1155,
+	'F': 147.1766, 'G': 57.0519, 'H': 137.1411, 'I': 113.1594,
+	'K': 128.1741, 'L': 113.1594, 'M': 131.1926, 'N': 114.1038,
+	'O': 237.2982, 'P': 97.1167, 'Q': 128.1307, 'R': 156.1875,
+	'S': 87.0782, 'T': 101.1051, 'U': 150.0388, 'V': 99.1326,
+	'W': 186.2132, 'Y': 163.1760,
+}
+
+var crcTable = crc64.MakeTable(crc64.ISO)
+
+// Database cross-reference (DR line)
+type XRef struct {
+	Database    string
+	Identifiers []string
+}
+
+// EMBL/UniProt record: the sequence (with its features) and header data
+type Record struct {
+	Seq       seq.Seq
+	Protein   bool
+	Accession []string
+	XRefs     []XRef
+	Topology  string
+	MolType   string
+	DataClass string
+	Division  string
+	Status    string
+}
+
+// EMBL sequence reader struct
+type Reader struct {
+	scan  seqitf.FileScanner
+	valid seqitf.Validator
+	eof   bool
+	line  int
+	count int
+}
+
+// EMBL sequence writer struct
+type Writer struct {
+	write seqitf.FileWriter
+	Count int
+}
+
+// Generate a new reader
+func NewReader(fs seqitf.FileScanner) *Reader {
+	return &Reader{
+		scan: fs,
+		eof:  false,
+	}
+}
+
+// Generate a new writer
+func NewWriter(fw seqitf.FileWriter) *Writer {
+	return &Writer{
+		write: fw,
+		Count: 0,
+	}
+}
+
+// Set the check of the sequence characters
+func (r *Reader) SetValidator(v seqitf.Validator) {
+	r.valid = v
+}
+
+// Return true if reachs the end-of-file
+func (r *Reader) IsEOF() bool {
+	return r.eof
+}
+
+// Locate an error in the input
+func (r *Reader) parseError(err error) error {
+	return seqitf.NewParseError("EMBL", r.line, r.count+1, err)
+}
+
+// Parse the ID line data of an EMBL or an UniProt entry
+func parseId(data string, rec *Record) error {
+	fields := strings.Fields(data)
+	if len(fields) < 2 {
+		return errors.New("Malformed ID line")
+	}
+	rec.Seq.SetId(strings.TrimSuffix(fields[0], ";"))
+	rec.Protein = strings.HasSuffix(data, "AA.")
+
+	if rec.Protein {
+		// UniProt: NAME Status; LENGTH AA.
+		rec.Status = strings.TrimSuffix(fields[1], ";")
+		return nil
+	}
+
+	// EMBL: ACC; SV n; topology; mol type; data class; division; LENGTH BP.
+	parts := strings.Split(data, ";")
+	if len(parts) == 7 {
+		rec.Topology = strings.TrimSpace(parts[2])
+		rec.MolType = strings.TrimSpace(parts[3])
+		rec.DataClass = strings.TrimSpace(parts[4])
+		rec.Division = strings.TrimSpace(parts[5])
+	}
+	return nil
+}
+
+// Parse the data of a DR line
+func parseXRef(data string) XRef {
+	parts := strings.Split(strings.TrimSuffix(data, "."), ";")
+	x := XRef{Database: strings.TrimSpace(parts[0])}
+	for _, p := range parts[1:] {
+		x.Identifiers = append(x.Identifiers, strings.TrimSpace(p))
+	}
+	return x
+}
+
+// Read a single EMBL/UniProt entry with its header data
+func (r *Reader) ReadRecord() (Record, error) {
+	rec, err := r.readRecord()
+	if err != nil {
+		// Scanning errors are returned as is
+		if r.scan.Err() != nil {
+			return rec, err
+		}
+		return rec, r.parseError(err)
+	}
+	if rec.Seq.Id != "" {
+		// Check the sequence characters
+		err = r.valid.Check(&rec.Seq)
+		if err != nil {
+			return rec, r.parseError(err)
+		}
+		r.count++
+	}
+	return rec, nil
+}
+
+func (r *Reader) readRecord() (Record, error) {
+	var rec Record
+	var def []string
+	started := false
+	table := feature.NewTableParser()
+
+	for r.scan.Scan() {
+		// Check possible scanning error
+		err := r.scan.Err()
+		if err != nil {
+			return rec, err
+		}
+
+		// Get the scanned line
+		line := string(r.scan.Bytes())
+		r.line++
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		// End of the record
+		if strings.HasPrefix(line, EndOfRecord) {
+			if !started {
+				return rec, errors.New("End of record without ID line")
+			}
+			rec.Seq.Desc = strings.Join(def, " ")
+			rec.Seq.Features, err = table.Features()
+			if err != nil {
+				return rec, err
+			}
+			if rec.Seq.Length() == 0 {
+				return rec, errors.New("Record without sequence (" + rec.Seq.Id + ")")
+			}
+			return rec, nil
+		}
+
+		if len(line) < 2 {
+			return rec, errors.New("Malformed line (" + line + ")")
+		}
+		code := line[:2]
+		var data string
+		if len(line) > DataIndent {
+			data = line[DataIndent:]
+		}
+
+		if code != "ID" && !started {
+			return rec, errors.New("Record must start with an ID line")
+		}
+
+		switch code {
+		case "ID":
+			if started {
+				return rec, errors.New("Missing end of record before ID line")
+			}
+			started = true
+			err = parseId(data, &rec)
+			if err != nil {
+				return rec, err
+			}
+		case "AC":
+			for _, ac := range strings.Split(data, ";") {
+				ac = strings.TrimSpace(ac)
+				if ac != "" {
+					rec.Accession = append(rec.Accession, ac)
+				}
+			}
+		case "DE":
+			def = append(def, strings.TrimSpace(data))
+		case "DR":
+			rec.XRefs = append(rec.XRefs, parseXRef(strings.TrimSpace(data)))
+		case "FT":
+			err = table.ParseLine(data)
+			if err != nil {
+				return rec, err
+			}
+		case "  ":
+			// Sequence data (positions and spaces are skipped)
+			for _, b := range []byte(data) {
+				if b == ' ' || (b >= '0' && b <= '9') {
+					continue
+				}
+				if b >= 'a' && b <= 'z' {
+					b -= 'a' - 'A'
+				}
+				rec.Seq.Sequence = append(rec.Seq.Sequence, b)
+			}
+		}
+	}
+	// Scanning is finished
+	r.eof = true
+
+	// Check possible scanning error
+	err := r.scan.Err()
+	if err != nil {
+		return rec, err
+	}
+
+	if started {
+		return rec, errors.New("Truncated record (" + rec.Seq.Id + ")")
+	}
+
+	// Return an empty sequence with no error
+	return rec, nil
+}
+
+// Read a single EMBL/UniProt entry
+func (r *Reader) Read() (seq.Seq, error) {
+	rec, err := r.ReadRecord()
+	return rec.Seq, err
+}
+
+// SWISS-PROT CRC64 (ISO polynomial, no pre/post inversion)
+func crc(s []byte) uint64 {
+	var c uint64
+	for _, b := range s {
+		c = crcTable[byte(c)^b] ^ (c >> 8)
+	}
+	return c
+}
+
+// Average molecular weight of a protein (unknown residues are ignored)
+func molWeight(s []byte) int {
+	w := 0.0
+	n := 0
+	for _, b := range bytes.ToUpper(s) {
+		if m, ok := aaMass[b]; ok {
+			w += m
+			n++
+		}
+	}
+	if n > 0 {
+		w += waterMass
+	}
+	return int(math.Round(w))
+}
+
+// Write lines with the given line code
+func (w *Writer) writeLines(code string, lines ...string) error {
+	for _, l := range lines {
+		if l != "" {
+			l = "   " + l
+		}
+		_, err := w.write.Write([]byte(code + l + "\n"))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Write a spacer line (EMBL only, UniProt does not use them)
+func (w *Writer) writeSpacer(protein bool) error {
+	if protein {
+		return nil
+	}
+	return w.writeLines("XX", "")
+}
+
+// Split a text in lines of words
+func wrapWords(text string) []string {
+	var lines []string
+	for _, word := range strings.Fields(text) {
+		last := len(lines) - 1
+		if last >= 0 && DataIndent+len(lines[last])+1+len(word) <= 80 {
+			lines[last] += " " + word
+		} else {
+			lines = append(lines, word)
+		}
+	}
+	return lines
+}
+
+// Write an EMBL/UniProt entry with its header data
+func (w *Writer) WriteRecord(rec Record) error {
+	s := rec.Seq
+	if s.Id == "" {
+		return errors.New("[EMBL WRITER]: Missing sequence ID.")
+	}
+	if s.Length() == 0 {
+		return fmt.Errorf("[EMBL WRITER]: %w.", seqitf.ErrEmptySequence)
+	}
+	protein := rec.Protein || seqitf.IsProtein(s.Sequence)
+	upper := bytes.ToUpper(s.Sequence)
+
+	// ID line
+	var err error
+	if protein {
+		status := rec.Status
+		if status == "" {
+			status = defaultStatus
+		}
+		err = w.writeLines("ID", fmt.Sprintf("%-24s%-14s%7d AA.", s.Id, status+";", s.Length()))
+	} else {
+		topology, molType, dataClass, division := rec.Topology, rec.MolType, rec.DataClass, rec.Division
+		if topology == "" {
+			topology = defaultTopology
+		}
+		if molType == "" {
+			molType = defaultMolType
+		}
+		if dataClass == "" {
+			dataClass = defaultDataClass
+		}
+		if division == "" {
+			division = defaultDivision
+		}
+		err = w.writeLines("ID", fmt.Sprintf("%s; SV 1; %s; %s; %s; %s; %d BP.",
+			s.Id, topology, molType, dataClass, division, s.Length()))
+	}
+	if err != nil {
+		return err
+	}
+	err = w.writeSpacer(protein)
+	if err != nil {
+		return err
+	}
+
+	// Accessions
+	accession := rec.Accession
+	if len(accession) == 0 {
+		accession = []string{s.Id}
+	}
+	err = w.writeLines("AC", strings.Join(accession, "; ")+";")
+	if err == nil {
+		err = w.writeSpacer(protein)
+	}
+	if err != nil {
+		return err
+	}
+
+	// Description
+	if s.Desc != "" {
+		err = w.writeLines("DE", wrapWords(s.Desc)...)
+		if err == nil {
+			err = w.writeSpacer(protein)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	// Cross-references
+	for _, x := range rec.XRefs {
+		err = w.writeLines("DR", x.Database+"; "+strings.Join(x.Identifiers, "; ")+".")
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(rec.XRefs) > 0 {
+		err = w.writeSpacer(protein)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Feature table
+	if len(s.Features) > 0 {
+		if !protein {
+			err = w.writeLines("FH", "Key             Location/Qualifiers", "")
+			if err != nil {
+				return err
+			}
+		}
+		for _, f := range s.Features {
+			for _, l := range f.Lines("FT   ") {
+				_, err = w.write.Write([]byte(l + "\n"))
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	// Sequence header
+	if protein {
+		err = w.writeLines("SQ", fmt.Sprintf("SEQUENCE %5d AA; %6d MW;  %016X CRC64;",
+			s.Length(), molWeight(upper), crc(upper)))
+	} else {
+		var a, c, g, t int
+		for _, b := range upper {
+			switch b {
+			case 'A':
+				a++
+			case 'C':
+				c++
+			case 'G':
+				g++
+			case 'T', 'U':
+				t++
+			}
+		}
+		if len(s.Features) > 0 {
+			err = w.writeSpacer(protein)
+		}
+		if err == nil {
+			err = w.writeLines("SQ", fmt.Sprintf("Sequence %d BP; %d A; %d C; %d G; %d T; %d other;",
+				s.Length(), a, c, g, t, s.Length()-a-c-g-t))
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	// Sequence lines (6 blocks of 10 residues, positions for nucleotides only)
+	out := upper
+	if !protein {
+		out = bytes.ToLower(s.Sequence)
+	}
+	var buf bytes.Buffer
+	for i := 0; i < len(out); i += LineLength {
+		buf.Reset()
+		for j := i; j < i+LineLength && j < len(out); j += BlockLength {
+			end := j + BlockLength
+			if end > len(out) {
+				end = len(out)
+			}
+			if j > i {
+				buf.WriteByte(' ')
+			}
+			buf.Write(out[j:end])
+		}
+		end := i + LineLength
+		if end > len(out) {
+			end = len(out)
+		}
+		var l string
+		if protein {
+			l = strings.Repeat(" ", DataIndent) + buf.String() + "\n"
+		} else {
+			l = fmt.Sprintf("%s%-65s%10d\n", strings.Repeat(" ", DataIndent), buf.String(), end)
+		}
+		_, err = w.write.Write([]byte(l))
+		if err != nil {
+			return err
+		}
+	}
+	_, err = w.write.Write([]byte(EndOfRecord + "\n"))
+	w.Count++
+
+	return err
+}
+
+// Write an EMBL/UniProt entry from a sequence
+func (w *Writer) Write(s seq.Seq) error {
+	return w.WriteRecord(Record{Seq: s})
+}
+
+func (w *Writer) Flush() error {
+	err := w.write.Flush()
+	return err
+}
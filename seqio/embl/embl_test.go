@@ -0,0 +1,183 @@
+package embl
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/hdevillers/go-seq/seqio/scanner"
+)
+
+// UniProtKB/Swiss-Prot entry P69905 (header excerpt, full sequence)
+const uniprotEntry = `ID   HBA_HUMAN               Reviewed;         142 AA.
+AC   P69905; P01922; Q1HDT5; Q3MIF5; Q53F97; Q96KF1; Q9NYR7; Q9UCM0;
+DT   21-JUL-1986, integrated into UniProtKB/Swiss-Prot.
+DE   RecName: Full=Hemoglobin subunit alpha;
+DE   AltName: Full=Alpha-globin;
+GN   Name=HBA1;
+OS   Homo sapiens (Human).
+OX   NCBI_TaxID=9606;
+DR   EMBL; V00493; CAA23752.1; -; mRNA.
+DR   PDB; 1A00; X-ray; 2.00 A; A/C=2-142.
+FT   CHAIN           2..142
+FT                   /note="Hemoglobin subunit alpha"
+FT                   /id="PRO_0000052653"
+FT   BINDING         59
+FT                   /ligand="heme b"
+SQ   SEQUENCE   142 AA;  15258 MW;  15E13666573BBBAE CRC64;
+     MVLSPADKTN VKAAWGKVGA HAGEYGAEAL ERMFLSFPTT KTYFPHFDLS HGSAQVKGHG
+     KKVADALTNA VAHVDDMPNA LSALSDLHAH KLRVDPVNFK LLSHCLLVTL AAHLPAEFTP
+     AVHASLDKFL ASVSTVLTSK YR
+//
+`
+
+// EMBL entry layout (X56734 header, first 120 bp, test feature table)
+const emblEntry = `ID   X56734; SV 1; linear; mRNA; STD; PLN; 120 BP.
+XX
+AC   X56734; S46826;
+XX
+DE   Trifolium repens mRNA for non-cyanogenic beta-glucosidase
+XX
+DR   MD5; 1e51ca3a5450c43524b9185c236cc5cc.
+XX
+FH   Key             Location/Qualifiers
+FH
+FT   source          1..120
+FT                   /organism="Trifolium repens"
+FT                   /mol_type="mRNA"
+FT                   /clone_lib="lambda gt10"
+FT                   /db_xref="taxon:3899"
+FT   CDS             join(14..40,51..>120)
+FT                   /codon_start=1
+FT                   /product="beta-glucosidase"
+FT                   /note="partial coding sequence used to check the feature
+FT                   table round trip of the EMBL reader and writer"
+FT                   /protein_id="CAA40058.1"
+FT   misc_feature    complement(order(2..10,60..70))
+FT                   /note="complement order"
+XX
+SQ   Sequence 120 BP; 36 A; 24 C; 17 G; 43 T; 0 other;
+     aaacaaacca aatatggatt ttattgtagc catatttgct ctgtttgtta ttagctcatt        60
+     cacaattact tccacaaatg cagttgaagc ttctactctt cttgacatag gtaacctgag       120
+//
+`
+
+func readRecord(t *testing.T, text string) Record {
+	t.Helper()
+	r := NewReader(scanner.NewScanner(strings.NewReader(text)))
+	rec, err := r.ReadRecord()
+	if err != nil {
+		t.Fatalf("ReadRecord: %v", err)
+	}
+	return rec
+}
+
+func writeRecord(t *testing.T, rec Record) string {
+	t.Helper()
+	var buf bytes.Buffer
+	w := NewWriter(bufio.NewWriter(&buf))
+	err := w.WriteRecord(rec)
+	if err == nil {
+		err = w.Flush()
+	}
+	if err != nil {
+		t.Fatalf("WriteRecord: %v", err)
+	}
+	return buf.String()
+}
+
+// Get the lines starting with one of the line codes
+func linesWith(text string, codes ...string) []string {
+	var lines []string
+	for _, l := range strings.Split(text, "\n") {
+		for _, c := range codes {
+			if strings.HasPrefix(l, c) {
+				lines = append(lines, l)
+				break
+			}
+		}
+	}
+	return lines
+}
+
+func TestUniProtEntry(t *testing.T) {
+	rec := readRecord(t, uniprotEntry)
+	if !rec.Protein || rec.Seq.Id != "HBA_HUMAN" || rec.Status != "Reviewed" {
+		t.Errorf("Protein, Id, Status = %v, %q, %q", rec.Protein, rec.Seq.Id, rec.Status)
+	}
+	if len(rec.Accession) != 8 || rec.Accession[0] != "P69905" {
+		t.Errorf("Accession = %v", rec.Accession)
+	}
+	if rec.Seq.Length() != 142 {
+		t.Errorf("Length = %d, want 142", rec.Seq.Length())
+	}
+	if len(rec.XRefs) != 2 || rec.XRefs[1].Database != "PDB" || len(rec.XRefs[1].Identifiers) != 4 {
+		t.Errorf("XRefs = %v", rec.XRefs)
+	}
+	if len(rec.Seq.Features) != 2 || rec.Seq.Features[0].Location != "2..142" {
+		t.Errorf("Features = %v", rec.Seq.Features)
+	}
+
+	// The ID, AC, DR, FT, SQ and sequence lines are written back as is
+	out := writeRecord(t, rec)
+	want := linesWith(uniprotEntry, "ID", "AC", "DR", "FT", "SQ", "  ", "//")
+	got := linesWith(out, "ID", "AC", "DR", "FT", "SQ", "  ", "//")
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("output:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestSequenceChecksum(t *testing.T) {
+	tests := []struct {
+		name string
+		seq  string
+		mw   int
+		crc  uint64
+	}{
+		{
+			"HBA_HUMAN",
+			"MVLSPADKTNVKAAWGKVGAHAGEYGAEALERMFLSFPTTKTYFPHFDLSHGSAQVKGHGKKVADALTNAVAHVDDMPNALSALSDLHAHKLRVDPVNFKLLSHCLLVTLAAHLPAEFTPAVHASLDKFLASVSTVLTSKYR",
+			15258,
+			0x15E13666573BBBAE,
+		},
+		{
+			"HBB_HUMAN",
+			"MVHLTPEEKSAVTALWGKVNVDEVGGEALGRLLVVYPWTQRFFESFGDLSTPDAVMGNPKVKAHGKKVLGAFSDGLAHLDNLKGTFATLSELHCDKLHVDPENFRLLGNVLVCVLAHHFGKEFTPPVQAAYQKVVAGVANALAHKYH",
+			15998,
+			0xA31F6D621C6556A1,
+		},
+	}
+	for _, tt := range tests {
+		if mw := molWeight([]byte(tt.seq)); mw != tt.mw {
+			t.Errorf("%s: MW = %d, want %d", tt.name, mw, tt.mw)
+		}
+		if c := crc([]byte(tt.seq)); c != tt.crc {
+			t.Errorf("%s: CRC64 = %016X, want %016X", tt.name, c, tt.crc)
+		}
+	}
+}
+
+func TestEmblRoundTrip(t *testing.T) {
+	rec := readRecord(t, emblEntry)
+	if rec.Protein || rec.MolType != "mRNA" || rec.Division != "PLN" {
+		t.Errorf("Protein, MolType, Division = %v, %q, %q", rec.Protein, rec.MolType, rec.Division)
+	}
+	locations := []string{"1..120", "join(14..40,51..>120)", "complement(order(2..10,60..70))"}
+	if len(rec.Seq.Features) != len(locations) {
+		t.Fatalf("%d features, want %d", len(rec.Seq.Features), len(locations))
+	}
+	for i, l := range locations {
+		if rec.Seq.Features[i].Location != l {
+			t.Errorf("feature %d location = %q, want %q", i, rec.Seq.Features[i].Location, l)
+		}
+	}
+	note, _ := rec.Seq.Features[1].Value("note")
+	if note != "partial coding sequence used to check the feature table round trip of the EMBL reader and writer" {
+		t.Errorf("note = %q", note)
+	}
+
+	if out := writeRecord(t, rec); out != emblEntry {
+		t.Errorf("output:\n%s\nwant:\n%s", out, emblEntry)
+	}
+}
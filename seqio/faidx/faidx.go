@@ -0,0 +1,275 @@
+package faidx
+
+import (
+	"errors"
+	"io"
+	"os"
+
+	"github.com/hdevillers/go-seq/seq"
+	"github.com/hdevillers/go-seq/seqio/bgzf"
+)
+
+const (
+	IndexSuffix string = ".fai"
+	GziSuffix   string = ".gzi"
+)
+
+// Random access FASTA reader struct
+type Reader struct {
+	data   io.ReaderAt
+	closer io.Closer
+	Index  *Index
+}
+
+// Check the compression of an opened file (rewound after the check)
+// NOTE: only BGZF compressed files can be indexed
+func checkCompression(f *os.File) (bool, error) {
+	head := make([]byte, bgzf.BlockHeaderLength)
+	n, err := io.ReadFull(f, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return false, err
+	}
+	_, err = f.Seek(0, io.SeekStart)
+	if err != nil {
+		return false, err
+	}
+	if bgzf.IsBgzf(head[:n]) {
+		return true, nil
+	}
+	if n >= 2 && head[0] == 31 && head[1] == 139 {
+		return false, errors.New("[FAIDX]: Compressed FASTA files must be BGZF compressed (bgzip).")
+	}
+	return false, nil
+}
+
+// Create the .fai index of a FASTA file (and the .gzi index for BGZF files)
+func Create(fasta string) error {
+	f, err := os.Open(fasta)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	isBgzf, err := checkCompression(f)
+	if err != nil {
+		return err
+	}
+
+	var idx *Index
+	if isBgzf {
+		gzi, err := bgzf.BuildIndex(f)
+		if err != nil {
+			return err
+		}
+		err = saveIndex(gzi, fasta+GziSuffix)
+		if err != nil {
+			return err
+		}
+		_, err = f.Seek(0, io.SeekStart)
+		if err != nil {
+			return err
+		}
+		idx, err = BuildIndex(bgzf.NewReader(f))
+		if err != nil {
+			return err
+		}
+	} else {
+		idx, err = BuildIndex(f)
+		if err != nil {
+			return err
+		}
+	}
+	return saveIndex(idx, fasta+IndexSuffix)
+}
+
+// Write out an index (.fai or .gzi) in a file
+func saveIndex(idx interface{ Write(io.Writer) error }, file string) error {
+	out, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	err = idx.Write(out)
+	if err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+func loadIndex(file string) (*Index, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadIndex(f)
+}
+
+func loadGzi(file string) (*bgzf.Index, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return bgzf.ReadIndex(f)
+}
+
+// Open an indexed FASTA file (plain or BGZF compressed)
+// NOTE: if the .fai (or .gzi) file does not exist, the index is built in memory
+func Open(fasta string) (*Reader, error) {
+	f, err := os.Open(fasta)
+	if err != nil {
+		return nil, err
+	}
+	r, err := open(f, fasta)
+	if err != nil {
+		f.Close()
+	}
+	return r, err
+}
+
+func open(f *os.File, fasta string) (*Reader, error) {
+	isBgzf, err := checkCompression(f)
+	if err != nil {
+		return nil, err
+	}
+
+	// Compressed block index
+	var gzi *bgzf.Index
+	if isBgzf {
+		if _, serr := os.Stat(fasta + GziSuffix); serr == nil {
+			gzi, err = loadGzi(fasta + GziSuffix)
+		} else {
+			gzi, err = bgzf.BuildIndex(f)
+			if err == nil {
+				_, err = f.Seek(0, io.SeekStart)
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Sequence index
+	var idx *Index
+	if _, serr := os.Stat(fasta + IndexSuffix); serr == nil {
+		idx, err = loadIndex(fasta + IndexSuffix)
+	} else if isBgzf {
+		idx, err = BuildIndex(bgzf.NewReader(f))
+	} else {
+		idx, err = BuildIndex(f)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if isBgzf {
+		return NewReader(bgzf.NewReaderAt(f, gzi), idx), nil
+	}
+	return NewReader(f, idx), nil
+}
+
+// Generate a new reader from random access data and its index
+func NewReader(data io.ReaderAt, idx *Index) *Reader {
+	r := Reader{
+		data:  data,
+		Index: idx,
+	}
+	if c, ok := data.(io.Closer); ok {
+		r.closer = c
+	}
+	return &r
+}
+
+// Close the underlying file
+func (r *Reader) Close() error {
+	if r.closer != nil {
+		return r.closer.Close()
+	}
+	return nil
+}
+
+// Get the names of the indexed sequences
+func (r *Reader) Names() []string {
+	names := make([]string, len(r.Index.Entries))
+	for i, e := range r.Index.Entries {
+		names[i] = e.Name
+	}
+	return names
+}
+
+// Parse a region string (name, name:start or name:start-end, 1-based)
+// and return the name and 0-based half-open coordinates
+// NOTE: end is set to -1 when not specified (see seq.ParseRegion)
+func ParseRegion(region string) (string, int64, int64, error) {
+	r, err := seq.ParseRegion(region)
+	if err != nil {
+		return r.Id, 0, 0, err
+	}
+	return r.Id, int64(r.Start), int64(r.End), nil
+}
+
+// Fetch a region given as a string (e.g. "chr1:1,000-2,000", 1-based)
+// NOTE: regions with a "(-)" suffix are reverse-complemented
+func (r *Reader) Fetch(region string) (seq.Seq, error) {
+	reg, err := seq.ParseRegion(region)
+	if err != nil {
+		return seq.Seq{}, err
+	}
+	// The whole region may be a sequence name containing ':'
+	if _, ok := r.Index.Get(reg.Id); !ok {
+		if _, ok := r.Index.Get(region); ok {
+			reg = seq.Region{Id: region, End: -1}
+		}
+	}
+	s, err := r.Subsequence(reg.Id, int64(reg.Start), int64(reg.End))
+	if err != nil {
+		return s, err
+	}
+	reg.End = reg.Start + s.Length()
+	if reg.Strand == '-' {
+		s.ReverseComplement()
+	}
+	s.SetId(reg.String())
+	return s, nil
+}
+
+// Fetch a subsequence from 0-based half-open coordinates
+// NOTE: end is bounded to the sequence length, -1 means up to the end
+func (r *Reader) Subsequence(name string, start, end int64) (seq.Seq, error) {
+	var s seq.Seq
+	e, ok := r.Index.Get(name)
+	if !ok {
+		return s, errors.New("[FAIDX]: Unknown sequence (" + name + ").")
+	}
+	if end < 0 || end > e.Length {
+		end = e.Length
+	}
+	if start < 0 || start >= end {
+		return s, errors.New("[FAIDX]: Empty or out of range region (" + name + ").")
+	}
+
+	// Read the raw bytes (including end-of-lines)
+	from := e.PosOffset(start)
+	to := e.PosOffset(end-1) + 1
+	raw := make([]byte, to-from)
+	_, err := r.data.ReadAt(raw, from)
+	if err != nil && err != io.EOF {
+		return s, err
+	}
+
+	// Remove the end-of-line characters
+	sequence := raw[:0]
+	for _, b := range raw {
+		if b != '\n' && b != '\r' {
+			sequence = append(sequence, b)
+		}
+	}
+	if int64(len(sequence)) != end-start {
+		return s, errors.New("[FAIDX]: Inconsistent index and FASTA file (" + name + ").")
+	}
+
+	s.SetId(name)
+	s.SetSequence(sequence)
+	return s, nil
+}
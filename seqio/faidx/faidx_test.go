@@ -0,0 +1,210 @@
+package faidx
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hdevillers/go-seq/seqio/bgzf"
+)
+
+// FASTA file with a varying last-line width, CRLF line endings and no final
+// end-of-line
+const testFasta = ">chr1 first sequence\nACGTACGTAC\nGTACGTACGT\nACG\n" +
+	">chr2\nAAAAAAAAAA\nCCCCCCCCCC\n" +
+	">chr3\nGGGG\n" +
+	">crlf\r\nACGTA\r\nCG\r\n" +
+	">last\nACGTAC\nTT"
+
+// Index of testFasta (samtools faidx values: name, length, offset of the
+// first base, bases and bytes per line)
+const testFai = "chr1\t23\t21\t10\t11\n" +
+	"chr2\t20\t53\t10\t11\n" +
+	"chr3\t4\t81\t4\t5\n" +
+	"crlf\t7\t93\t5\t7\n" +
+	"last\t8\t110\t6\t7\n"
+
+// Write a file in a temporary directory
+func writeFile(t *testing.T, name string, data []byte) string {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(file, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+// BGZF compress data
+func compress(t *testing.T, data []byte) []byte {
+	t.Helper()
+	var out bytes.Buffer
+	w := bgzf.NewWriter(&out)
+	w.Write(data)
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return out.Bytes()
+}
+
+// Large FASTA file (several BGZF blocks) and its sequences
+func largeFasta() (string, map[string]string) {
+	seqs := map[string]string{
+		"s1": strings.Repeat("ACGTTGCAAC", 9000) + "ACG",
+		"s2": strings.Repeat("TTGGCCAAGT", 8000),
+	}
+	var b strings.Builder
+	for _, name := range []string{"s1", "s2"} {
+		b.WriteString(">" + name + "\n")
+		s := seqs[name]
+		for i := 0; i < len(s); i += 60 {
+			end := i + 60
+			if end > len(s) {
+				end = len(s)
+			}
+			b.WriteString(s[i:end] + "\n")
+		}
+	}
+	return b.String(), seqs
+}
+
+func TestBuildIndex(t *testing.T) {
+	idx, err := BuildIndex(strings.NewReader(testFasta))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if err = idx.Write(&out); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != testFai {
+		t.Errorf("index:\n%s\nwant:\n%s", out.String(), testFai)
+	}
+
+	// Read back the index
+	read, err := ReadIndex(strings.NewReader(testFai))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e, ok := read.Get("crlf"); !ok || e != idx.Entries[3] {
+		t.Errorf("ReadIndex: %v, %v", e, ok)
+	}
+}
+
+func TestInconsistentLines(t *testing.T) {
+	bad := []string{
+		">x\nACGT\nAC\nACGT\n",
+		">x\nACGT\nACGTA\n",
+		">x\nACGT\n\nACGT\n",
+		">x\nACGT\r\nACGT\nAC\n",
+		">x\nACGT\n>x\nACGT\n",
+		"ACGT\n>x\nACGT\n",
+	}
+	for _, f := range bad {
+		if _, err := BuildIndex(strings.NewReader(f)); err == nil {
+			t.Errorf("no error with %q", f)
+		}
+	}
+
+	// Empty lines at the end of a sequence are allowed
+	if _, err := BuildIndex(strings.NewReader(">x\nACGT\nAC\n\n>y\nA\n")); err != nil {
+		t.Errorf("error with a trailing empty line: %v", err)
+	}
+}
+
+func TestCreateAndFetch(t *testing.T) {
+	file := writeFile(t, "test.fa", []byte(testFasta))
+	if err := Create(file); err != nil {
+		t.Fatal(err)
+	}
+	fai, err := os.ReadFile(file + IndexSuffix)
+	if err != nil || string(fai) != testFai {
+		t.Fatalf(".fai = %q, %v", fai, err)
+	}
+
+	r, err := Open(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	tests := []struct {
+		region, id, seq string
+	}{
+		{"chr1", "chr1:1-23", "ACGTACGTACGTACGTACGTACG"},
+		{"chr1:9-13", "chr1:9-13", "ACGTA"},
+		{"chr1:20", "chr1:20-23", "TACG"},
+		{"chr1:9-13(-)", "chr1:9-13(-)", "TACGT"},
+		{"chr2:8-12(+)", "chr2:8-12(+)", "AAACC"},
+		{"chr2:8-12(-)", "chr2:8-12(-)", "GGTTT"},
+		{"crlf:4-7", "crlf:4-7", "TACG"},
+		{"last:5-100", "last:5-8", "ACTT"},
+	}
+	for _, tt := range tests {
+		s, err := r.Fetch(tt.region)
+		if err != nil {
+			t.Errorf("Fetch(%s): %v", tt.region, err)
+			continue
+		}
+		if s.Id != tt.id || string(s.Sequence) != tt.seq {
+			t.Errorf("Fetch(%s) = %s %s, want %s %s", tt.region, s.Id, s.Sequence, tt.id, tt.seq)
+		}
+	}
+	for _, region := range []string{"chr4", "chr1:24-30", "chr1:0-3", "chr1:5-2"} {
+		if _, err := r.Fetch(region); err == nil {
+			t.Errorf("Fetch(%s): no error", region)
+		}
+	}
+}
+
+func TestBgzfFasta(t *testing.T) {
+	text, seqs := largeFasta()
+	plain := writeFile(t, "large.fa", []byte(text))
+	gz := writeFile(t, "large.fa.gz", compress(t, []byte(text)))
+	for _, f := range []string{plain, gz} {
+		if err := Create(f); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Same .fai for the plain and the compressed files
+	fai1, _ := os.ReadFile(plain + IndexSuffix)
+	fai2, _ := os.ReadFile(gz + IndexSuffix)
+	want := "s1\t90003\t4\t60\t61\ns2\t80000\t91512\t60\t61\n"
+	if string(fai1) != want || string(fai2) != want {
+		t.Errorf(".fai = %q and %q, want %q", fai1, fai2, want)
+	}
+	if _, err := os.Stat(gz + GziSuffix); err != nil {
+		t.Errorf("missing .gzi file: %v", err)
+	}
+
+	// Regions across the BGZF blocks (0xff00 bytes)
+	r, err := Open(gz)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	for _, reg := range []struct {
+		name       string
+		start, end int64
+	}{{"s1", 0, 10}, {"s1", 64000, 66000}, {"s1", 89990, 90003}, {"s2", 39000, 41000}, {"s2", 0, 80000}} {
+		s, err := r.Subsequence(reg.name, reg.start, reg.end)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(s.Sequence) != seqs[reg.name][reg.start:reg.end] {
+			t.Errorf("Subsequence(%s, %d, %d) differs", reg.name, reg.start, reg.end)
+		}
+	}
+	s, err := r.Fetch("s2:1-10(-)")
+	if err != nil || string(s.Sequence) != "ACTTGGCCAA" {
+		t.Errorf("Fetch(s2:1-10(-)) = %s, %v", s.Sequence, err)
+	}
+
+	// Only BGZF compression can be indexed
+	var out bytes.Buffer
+	out.Write([]byte{31, 139, 8, 0, 0, 0, 0, 0, 0, 0xff})
+	if err = Create(writeFile(t, "bad.fa.gz", out.Bytes())); err == nil {
+		t.Errorf("no error with a gzip file")
+	}
+}
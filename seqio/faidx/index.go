@@ -0,0 +1,193 @@
+package faidx
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+/*
+	samtools compatible FASTA index (.fai): one line per sequence with
+	NAME, LENGTH, OFFSET (of the first base), LINEBASES and LINEWIDTH
+	(line length including the end-of-line characters).
+*/
+
+// Index entry of a single sequence
+type Entry struct {
+	Name      string
+	Length    int64
+	Offset    int64
+	LineBases int64
+	LineWidth int64
+}
+
+// FASTA index
+type Index struct {
+	Entries []Entry
+	names   map[string]int
+}
+
+// Generate a new (empty) index
+func NewIndex() *Index {
+	return &Index{
+		names: make(map[string]int),
+	}
+}
+
+// Add an entry in the index
+func (idx *Index) Add(e Entry) error {
+	if _, ok := idx.names[e.Name]; ok {
+		return errors.New("[FAIDX]: Duplicated sequence name (" + e.Name + ").")
+	}
+	idx.names[e.Name] = len(idx.Entries)
+	idx.Entries = append(idx.Entries, e)
+	return nil
+}
+
+// Get an entry from its sequence name
+func (idx *Index) Get(name string) (Entry, bool) {
+	i, ok := idx.names[name]
+	if !ok {
+		return Entry{}, false
+	}
+	return idx.Entries[i], true
+}
+
+// Offset (in the uncompressed file) of a 0-based position
+func (e *Entry) PosOffset(pos int64) int64 {
+	return e.Offset + (pos/e.LineBases)*e.LineWidth + pos%e.LineBases
+}
+
+// Build the index of a FASTA stream
+func BuildIndex(r io.Reader) (*Index, error) {
+	idx := NewIndex()
+	br := bufio.NewReader(r)
+	var curr *Entry
+	var offset int64
+	lastLine := false // a shorter line was found in the current sequence
+	nline := 0
+
+	closeEntry := func() error {
+		if curr == nil {
+			return nil
+		}
+		err := idx.Add(*curr)
+		curr = nil
+		return err
+	}
+
+	for {
+		line, err := br.ReadSlice('\n')
+		if err == bufio.ErrBufferFull {
+			// Long lines: keep reading until the end of the line
+			full := append([]byte(nil), line...)
+			for err == bufio.ErrBufferFull {
+				line, err = br.ReadSlice('\n')
+				full = append(full, line...)
+			}
+			line = full
+		}
+		if err != nil && err != io.EOF {
+			return idx, err
+		}
+		if len(line) == 0 {
+			break
+		}
+		nline++
+		width := int64(len(line))
+		bases := int64(len(strings.TrimRight(string(line), "\r\n")))
+
+		if line[0] == '>' {
+			cerr := closeEntry()
+			if cerr != nil {
+				return idx, cerr
+			}
+			name := strings.Fields(string(line[1:]))
+			if len(name) == 0 {
+				return idx, fmt.Errorf("[FAIDX]: Sequence without name (line %d).", nline)
+			}
+			curr = &Entry{Name: name[0], Offset: offset + width}
+			lastLine = false
+		} else if curr == nil {
+			if bases > 0 {
+				return idx, fmt.Errorf("[FAIDX]: Sequence data before the first header (line %d).", nline)
+			}
+		} else if bases == 0 {
+			// Empty lines are only allowed at the end of a sequence
+			lastLine = true
+		} else {
+			if lastLine {
+				return idx, fmt.Errorf("[FAIDX]: Different line length in sequence %s (line %d).", curr.Name, nline)
+			}
+			if curr.LineBases == 0 {
+				curr.LineBases = bases
+				curr.LineWidth = width
+			} else if bases > curr.LineBases || (bases == curr.LineBases && width != curr.LineWidth && err != io.EOF) {
+				return idx, fmt.Errorf("[FAIDX]: Different line length in sequence %s (line %d).", curr.Name, nline)
+			} else if bases < curr.LineBases {
+				lastLine = true
+			}
+			curr.Length += bases
+		}
+
+		offset += width
+		if err == io.EOF {
+			break
+		}
+	}
+
+	err := closeEntry()
+	return idx, err
+}
+
+// Read a .fai file
+func ReadIndex(r io.Reader) (*Index, error) {
+	idx := NewIndex()
+	scan := bufio.NewScanner(r)
+	nline := 0
+	for scan.Scan() {
+		nline++
+		line := scan.Text()
+		if line == "" {
+			continue
+		}
+		data := strings.Split(line, "\t")
+		if len(data) < 5 {
+			return idx, fmt.Errorf("[FAIDX]: Malformed index line (line %d).", nline)
+		}
+		var val [4]int64
+		for i := range val {
+			v, err := strconv.ParseInt(data[i+1], 10, 64)
+			if err != nil {
+				return idx, fmt.Errorf("[FAIDX]: Malformed index line (line %d).", nline)
+			}
+			val[i] = v
+		}
+		err := idx.Add(Entry{
+			Name:      data[0],
+			Length:    val[0],
+			Offset:    val[1],
+			LineBases: val[2],
+			LineWidth: val[3],
+		})
+		if err != nil {
+			return idx, err
+		}
+	}
+	return idx, scan.Err()
+}
+
+// Write the index in .fai format
+func (idx *Index) Write(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	for _, e := range idx.Entries {
+		_, err := fmt.Fprintf(bw, "%s\t%d\t%d\t%d\t%d\n", e.Name, e.Length, e.Offset, e.LineBases, e.LineWidth)
+		if err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
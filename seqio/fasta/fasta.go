@@ -2,6 +2,7 @@ package fasta
 
 import (
 	"errors"
+	"strconv"
 	"strings"
 
 	"github.com/hdevillers/go-seq/seq"
@@ -11,47 +12,167 @@ import (
 const (
 	IdPreffix  byte = '>'
 	LineLength int  = 60
+	NoWrap     int  = -1
 )
 
+// Letter case of the written sequences
+type Case int
+
+const (
+	KeepCase Case = iota
+	UpperCase
+	LowerCase
+)
+
+var errNoId = errors.New("Sequence without ID or possible bad format")
+
+var newLine = []byte{'\n'}
+
 // Fasta sequence reader struct
 type Reader struct {
-	scan     seqitf.FileScanner
-	currId   string
-	currDesc string
-	eof      bool
+	scan  seqitf.FileScanner
+	head  seqitf.HeadBuffer
+	valid seqitf.Validator
+	eof   bool
+	line  int
+	count int
 }
 
 // Fasta sequence write struct
 type Writer struct {
-	write seqitf.FileWriter
-	Count int
+	write  seqitf.FileWriter
+	width  int
+	lcase  Case
+	header []headerField
+	buf    []byte
+	Count  int
+}
+
+// Fasta writer options
+// NOTE: the zero value gives the default output (60 bases per line, case
+// unchanged, ">ID DESC" header)
+type WriterOptions struct {
+	// Number of bases per line (0: LineLength, NoWrap: single line)
+	LineWidth int
+	// Letter case of the sequence
+	Case Case
+	// Header line template (without '>'), the fields {id}, {desc} and
+	// {length} are replaced by the sequence values
+	Header string
+}
+
+// Element of a header template (literal text or field)
+type headerField struct {
+	text  string
+	field string
 }
 
 // Generate a new reader
 func NewReader(fs seqitf.FileScanner) *Reader {
 	return &Reader{
-		scan:     fs,
-		currId:   "",
-		currDesc: "",
-		eof:      false,
+		scan: fs,
+		eof:  false,
 	}
 }
 
 // Generate a new writer
 //func NewWriter(wf *bufio.Writer) *Writer {
 func NewWriter(fw seqitf.FileWriter) *Writer {
+	return NewWriterWithOptions(fw, WriterOptions{})
+}
+
+// Generate a new writer with options
+func NewWriterWithOptions(fw seqitf.FileWriter, opt WriterOptions) *Writer {
+	width := opt.LineWidth
+	if width == 0 {
+		width = LineLength
+	}
 	return &Writer{
-		write: fw,
-		Count: 0,
+		write:  fw,
+		width:  width,
+		lcase:  opt.Case,
+		header: parseHeaderTemplate(opt.Header),
+		Count:  0,
+	}
+}
+
+// Split a header template into literal texts and fields
+func parseHeaderTemplate(tmpl string) []headerField {
+	var fields []headerField
+	for len(tmpl) > 0 {
+		i := strings.IndexByte(tmpl, '{')
+		j := -1
+		if i >= 0 {
+			j = strings.IndexByte(tmpl[i:], '}')
+		}
+		if i < 0 || j < 0 {
+			fields = append(fields, headerField{text: tmpl})
+			break
+		}
+		j += i
+		switch name := tmpl[i+1 : j]; name {
+		case "id", "desc", "length":
+			if i > 0 {
+				fields = append(fields, headerField{text: tmpl[:i]})
+			}
+			fields = append(fields, headerField{field: name})
+		default:
+			// Unknown field, keep it as it is
+			fields = append(fields, headerField{text: tmpl[:j+1]})
+		}
+		tmpl = tmpl[j+1:]
 	}
+	return fields
 }
 
-func parseIdLine(idl string) (string, string) {
-	data := strings.SplitN(idl, " ", 2)
-	if len(data) == 2 {
-		return data[0], data[1]
+// Build the header line of a sequence
+func (w *Writer) appendHeader(b []byte, s *seq.Seq) []byte {
+	b = append(b, IdPreffix)
+	if w.header == nil {
+		b = append(b, s.Id...)
+		if s.Desc != "" {
+			b = append(b, ' ')
+			b = append(b, s.Desc...)
+		}
+		return append(b, '\n')
+	}
+	for _, f := range w.header {
+		switch f.field {
+		case "id":
+			b = append(b, s.Id...)
+		case "desc":
+			b = append(b, s.Desc...)
+		case "length":
+			b = strconv.AppendInt(b, int64(s.Length()), 10)
+		default:
+			b = append(b, f.text...)
+		}
+	}
+	return append(b, '\n')
+}
+
+// Change the case of a block of sequence
+func (w *Writer) convertCase(b []byte) []byte {
+	w.buf = append(w.buf[:0], b...)
+	if w.lcase == UpperCase {
+		for i, c := range w.buf {
+			if c >= 'a' && c <= 'z' {
+				w.buf[i] = c - 32
+			}
+		}
+	} else {
+		for i, c := range w.buf {
+			if c >= 'A' && c <= 'Z' {
+				w.buf[i] = c + 32
+			}
+		}
 	}
-	return data[0], ""
+	return w.buf
+}
+
+// Set the check of the sequence characters
+func (r *Reader) SetValidator(v seqitf.Validator) {
+	r.valid = v
 }
 
 // Return true if reachs the end-of-file
@@ -59,72 +180,98 @@ func (r *Reader) IsEOF() bool {
 	return r.eof
 }
 
+// Locate an error in the input
+func (r *Reader) parseError(err error) error {
+	return seqitf.NewParseError("FASTA", r.line, r.count+1, err)
+}
+
 // Read a single fasta entry
 func (r *Reader) Read() (seq.Seq, error) {
-	// Initialize the new sequence
 	var newSeq seq.Seq
+	err := r.read(&newSeq, false)
+	return newSeq, err
+}
 
-	for r.scan.Scan() {
-		// Check possible scanning error
-		err := r.scan.Err()
-		if err != nil {
-			return newSeq, err
-		}
+// Read a single fasta entry into an existing sequence (memory reuse)
+func (r *Reader) ReadInto(s *seq.Seq) error {
+	s.Reset()
+	return r.read(s, true)
+}
 
+func (r *Reader) read(newSeq *seq.Seq, shared bool) error {
+	for r.scan.Scan() {
 		// Get the scanned line
 		line := r.scan.Bytes()
+		r.line++
 
 		// FIX: can have an empty line at the end of the file
 		if len(line) > 0 {
 			if line[0] == IdPreffix {
 				// This is an ID line
-				if r.currId != "" {
+				if r.head.IsSet() {
 					// Return the current sequence if not nil
 					if newSeq.Length() == 0 {
 						// Empty sequence or bad format
-						return newSeq, errors.New("[FASTA READER]: Empty sequence or bad format.")
+						return r.parseError(seqitf.ErrEmptySequence)
 					}
 
 					// Set sequence data
-					newSeq.SetId(r.currId)
-					newSeq.SetDesc(r.currDesc)
+					newSeq.Id, newSeq.Desc = seqitf.SplitIdLine(r.head.Pop(shared))
 
 					// Save the new ID
-					r.currId, r.currDesc = parseIdLine(string(line[1:]))
+					r.head.Set(line[1:])
 
 					// Return the completed sequence
-					return newSeq, nil
+					r.count++
+					return nil
 				} else {
 					// Save the new ID
-					r.currId, r.currDesc = parseIdLine(string(line[1:]))
+					r.head.Set(line[1:])
 
 					// Thow an error if the sequence is not nil
 					if newSeq.Length() > 0 {
-						return newSeq, errors.New("[FASTA READER]: Sequence without ID or possible bad format.")
+						return r.parseError(errNoId)
 					}
 
 					// Continue
 				}
 			} else {
-				//TODO: Control input character
-				newSeq.AppendSequence(line)
+				// Check the input characters
+				err := r.valid.Append(newSeq, line)
+				if err != nil {
+					return r.parseError(err)
+				}
 			}
 		}
 	}
 	// Scanning is finicher
 	r.eof = true
 
+	// Check possible scanning error
+	err := r.scan.Err()
+	if err != nil {
+		return err
+	}
+
+	// Empty input
+	if !r.head.IsSet() {
+		if newSeq.Length() > 0 {
+			return r.parseError(errNoId)
+		}
+		return nil
+	}
+
 	// Set last sequence ID and Description
-	newSeq.SetId(r.currId)
-	newSeq.SetDesc(r.currDesc)
+	newSeq.Id, newSeq.Desc = seqitf.SplitIdLine(r.head.Pop(shared))
 
 	// Check if the last sequence is empty
 	if newSeq.Length() == 0 {
-		return newSeq, errors.New("[FASTA READER]: The last sequence is empty.")
+		return r.parseError(seqitf.ErrEmptySequence)
 	}
 
 	// Return with no error
-	return newSeq, nil
+	r.count++
+	return nil
 }
 
 func (w *Writer) Write(s seq.Seq) error {
@@ -132,43 +279,40 @@ func (w *Writer) Write(s seq.Seq) error {
 	if s.Id == "" {
 		return errors.New("[FASTA WRITER]: Missing sequence ID.")
 	}
-	_, err := w.write.Write([]byte(">" + s.Id))
+	w.buf = w.appendHeader(w.buf[:0], &s)
+	_, err := w.write.Write(w.buf)
 	if err != nil {
 		return err
 	}
 
-	// Add the description
-	if s.Desc != "" {
-		_, err = w.write.Write([]byte(" " + s.Desc))
+	// Add the sequence by blocks of one line
+	width := w.width
+	if width < 0 {
+		width = s.Length()
+	}
+	for i := 0; i < s.Length(); i += width {
+		end := i + width
+		if end > s.Length() {
+			end = s.Length()
+		}
+		block := s.Sequence[i:end]
+		if w.lcase != KeepCase {
+			block = w.convertCase(block)
+		}
+		_, err = w.write.Write(block)
 		if err != nil {
 			return err
 		}
-	}
-	_, err = w.write.Write([]byte{'\n'})
-	if err != nil {
-		return err
-	}
-
-	// Add the sequence
-	// NOTE: We assume that if no error occured above, io.writer is OK
-	n := 0
-	for i := 0; i < s.Length(); i++ {
-		w.write.Write([]byte{s.Sequence[i]})
-		n++
-		if n == LineLength {
-			w.write.Write([]byte{'\n'})
-			n = 0
+		_, err = w.write.Write(newLine)
+		if err != nil {
+			return err
 		}
 	}
-	if n != 0 {
-		w.write.Write([]byte{'\n'})
-	}
 
-	// Flush written bytes
-	err = w.write.Flush()
+	// NOTE: the output is flushed by Flush (not after each record)
 	w.Count++
 
-	return err
+	return nil
 }
 
 func (w *Writer) Flush() error {
@@ -0,0 +1,78 @@
+package fasta
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/hdevillers/go-seq/seq"
+	"github.com/hdevillers/go-seq/seqio/scanner"
+)
+
+func TestReadLongLine(t *testing.T) {
+	// Unwrapped sequence larger than bufio.Scanner's 64 KiB limit
+	long := strings.Repeat("ACGTN", 40000)
+	text := ">long first\n" + long + "\n>short\nAC\r\nGT\n"
+	r := NewReader(scanner.NewScanner(strings.NewReader(text)))
+
+	s, err := r.Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Id != "long" || s.Desc != "first" || string(s.Sequence) != long {
+		t.Errorf("Read = %s %s (%d bp), want long first (%d bp)", s.Id, s.Desc, s.Length(), len(long))
+	}
+	s, err = r.Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Id != "short" || string(s.Sequence) != "ACGT" {
+		t.Errorf("Read = %s %s, want short ACGT", s.Id, s.Sequence)
+	}
+	s, err = r.Read()
+	if err != nil || s.Length() != 0 || !r.IsEOF() {
+		t.Errorf("end of file: %v, %d, %v", err, s.Length(), r.IsEOF())
+	}
+}
+
+func TestWriterOptions(t *testing.T) {
+	long := strings.Repeat("ACGTACGTAC", 13)
+	tests := []struct {
+		name string
+		opt  WriterOptions
+		seq  string
+		out  string
+	}{
+		{"default", WriterOptions{}, long, ">s first\n" + long[:60] + "\n" + long[60:120] + "\n" + long[120:] + "\n"},
+		{"width", WriterOptions{LineWidth: 4}, "ACGTACGTAC", ">s first\nACGT\nACGT\nAC\n"},
+		{"full lines", WriterOptions{LineWidth: 4}, "ACGTACGT", ">s first\nACGT\nACGT\n"},
+		{"no wrap", WriterOptions{LineWidth: NoWrap}, long, ">s first\n" + long + "\n"},
+		{"keep case", WriterOptions{}, "ACgtN", ">s first\nACgtN\n"},
+		{"lower case", WriterOptions{LineWidth: 3, Case: LowerCase}, "ACgtN-", ">s first\nacg\ntn-\n"},
+		{"upper case", WriterOptions{Case: UpperCase}, "acGTn*", ">s first\nACGTN*\n"},
+		{"header", WriterOptions{Header: "{id}|len={length}|{foo} {desc"}, "ACGT", ">s|len=4|{foo} {desc\nACGT\n"},
+		{"header fields", WriterOptions{Header: "{desc}:{id}"}, "ACGT", ">first:s\nACGT\n"},
+	}
+	for _, tt := range tests {
+		var b bytes.Buffer
+		out := bufio.NewWriter(&b)
+		w := NewWriterWithOptions(out, tt.opt)
+		sq := []byte(tt.seq)
+		if err := w.Write(seq.Seq{Id: "s", Desc: "first", Sequence: sq}); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		w.Flush()
+		if b.String() != tt.out {
+			t.Errorf("%s: %q, want %q", tt.name, b.String(), tt.out)
+		}
+		if string(sq) != tt.seq {
+			t.Errorf("%s: the sequence was modified: %s", tt.name, sq)
+		}
+	}
+
+	w := NewWriter(bufio.NewWriter(&bytes.Buffer{}))
+	if err := w.Write(seq.Seq{Sequence: []byte("ACGT")}); err == nil || w.Count != 0 {
+		t.Errorf("no error without ID")
+	}
+}
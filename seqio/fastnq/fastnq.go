@@ -1,6 +1,10 @@
 package fastnq
 
 import (
+	"bytes"
+	"errors"
+	"io"
+
 	"github.com/hdevillers/go-seq/seq"
 	"github.com/hdevillers/go-seq/seqio/seqitf"
 )
@@ -8,65 +12,275 @@ import (
 const (
 	IdPreffix byte = '@'
 	SpPreffix byte = '+'
+	BufSize   int  = 1 << 20
+)
+
+var (
+	errNoId       = errors.New("Misaligned record, ID line expected")
+	errNoSpacer   = errors.New("Misaligned record, missing '+' spacer line")
+	errQualLength = errors.New("Sequence and quality with different lengths")
+	errTruncated  = errors.New("Truncated record")
 )
 
+/*
+	NOTE: this parser is made to save time:
+	1) lines are located directly in a large buffer (no line scanner) with
+	   the reader created by NewBufferedReader
+	2) quality is skipped by default (only its length is checked)
+	3) the record structure is still checked (truncated or misaligned
+	   records are reported)
+*/
+
 // Fastq sequence reader struct
 type Reader struct {
 	scan     seqitf.FileScanner
-	currId   string
+	in       io.Reader
+	buf      []byte
+	pos      int
+	end      int
+	rerr     error
+	head     seqitf.HeadBuffer
+	valid    seqitf.Validator
 	eof      bool
-	waitQual bool
+	keepQual bool
+	strict   bool
+	warn     error
+	phred    int
+	line     int
+	count    int
 }
 
 // Generate a new reader
 func NewReader(fs seqitf.FileScanner) *Reader {
 	return &Reader{
-		scan:     fs,
-		currId:   "",
-		eof:      false,
-		waitQual: false,
+		scan: fs,
+		eof:  false,
 	}
 }
 
+// Generate a new reader locating the lines in its own buffer (faster)
+func NewBufferedReader(in io.Reader) *Reader {
+	return &Reader{
+		in:  in,
+		buf: make([]byte, BufSize),
+		eof: false,
+	}
+}
+
+// Keep (or skip) the quality of the sequences
+// NOTE: the scores are decoded as with the fastq reader (the PHRED type is
+// determined once per file)
+func (r *Reader) SetKeepQuality(keep bool) {
+	r.keepQual = keep
+}
+
+// Only accept four-line records
+func (r *Reader) SetStrict(strict bool) {
+	r.strict = strict
+}
+
+// Set the check of the sequence characters
+func (r *Reader) SetValidator(v seqitf.Validator) {
+	r.valid = v
+}
+
 // Return true if reachs the end-of-file
 func (r *Reader) IsEOF() bool {
 	return r.eof
 }
 
+// Return the quality warning of the last read sequence (nil if none)
+// NOTE: quality warnings are not fatal (e.g., inconsistent PHRED values)
+func (r *Reader) Warning() error {
+	return r.warn
+}
+
+// Locate an error in the input
+func (r *Reader) parseError(err error) error {
+	return seqitf.NewParseError("FASTNQ", r.line, r.count+1, err)
+}
+
+// Load more data in the buffer, return false if nothing can be loaded
+func (r *Reader) fill() bool {
+	if r.rerr != nil {
+		return false
+	}
+
+	// Move the remaining data at the beginning of the buffer
+	if r.pos > 0 {
+		r.end = copy(r.buf, r.buf[r.pos:r.end])
+		r.pos = 0
+	}
+
+	// Grow the buffer if a line is larger than the buffer
+	if r.end == len(r.buf) {
+		nbuf := make([]byte, 2*len(r.buf))
+		copy(nbuf, r.buf[:r.end])
+		r.buf = nbuf
+	}
+
+	n, err := r.in.Read(r.buf[r.end:])
+	r.end += n
+	if err != nil {
+		r.rerr = err
+	}
+	return n > 0 || err == nil
+}
+
+// Get the next line (without line ending)
+// NOTE: the returned slice is only valid until the next call
+func (r *Reader) next() ([]byte, bool) {
+	if r.scan != nil {
+		if !r.scan.Scan() {
+			return nil, false
+		}
+		r.line++
+		return r.scan.Bytes(), true
+	}
+	for {
+		i := bytes.IndexByte(r.buf[r.pos:r.end], '\n')
+		if i >= 0 {
+			line := r.buf[r.pos : r.pos+i]
+			r.pos += i + 1
+			r.line++
+			return dropCR(line), true
+		}
+		if !r.fill() {
+			break
+		}
+	}
+
+	// Last line without line ending
+	if r.pos < r.end {
+		line := r.buf[r.pos:r.end]
+		r.pos = r.end
+		r.line++
+		return dropCR(line), true
+	}
+	return nil, false
+}
+
+// Remove the trailing \r of a line
+func dropCR(line []byte) []byte {
+	if len(line) > 0 && line[len(line)-1] == '\r' {
+		return line[:len(line)-1]
+	}
+	return line
+}
+
+// Return the input error (nil at the end of the input)
+func (r *Reader) readErr() error {
+	if r.scan != nil {
+		return r.scan.Err()
+	}
+	if r.rerr == io.EOF {
+		return nil
+	}
+	return r.rerr
+}
+
+// Report a record interrupted by the end of the input
+func (r *Reader) truncated() error {
+	r.eof = true
+	if err := r.readErr(); err != nil {
+		return err
+	}
+	return r.parseError(errTruncated)
+}
+
 // Read a single fastq entry
 func (r *Reader) Read() (seq.Seq, error) {
-	// Initialize the new sequence
 	var newSeq seq.Seq
+	err := r.read(&newSeq, false)
+	return newSeq, err
+}
 
-	/*
-		NOTE: this parser version is made to save time:
-		1) Quality is not considered
-		2) Fastq file is supposed well formed
-	*/
-
-	for r.scan.Scan() {
-		// Check possible scanning error
-		err := r.scan.Err()
-		if err != nil {
-			return newSeq, err
-		}
+// Read a single fastq entry into an existing sequence (memory reuse)
+func (r *Reader) ReadInto(s *seq.Seq) error {
+	s.Reset()
+	return r.read(s, true)
+}
 
-		// Get the ID line
-		line := r.scan.Bytes()
-		newSeq.SetId(string(line[1:]))
+func (r *Reader) read(newSeq *seq.Seq, shared bool) error {
+	r.warn = nil
 
-		// Get the sequence line
-		r.scan.Scan()
-		line = r.scan.Bytes()
-		newSeq.AppendSequence(line)
+	// Get the ID line (skip empty lines)
+	var line []byte
+	var ok bool
+	for {
+		line, ok = r.next()
+		if !ok {
+			r.eof = true
+			return r.readErr()
+		}
+		if len(line) > 0 {
+			break
+		}
+	}
+	if line[0] != IdPreffix || len(line) == 1 {
+		return r.parseError(errNoId)
+	}
+	r.head.Set(line[1:])
+	newSeq.Id, newSeq.Desc = seqitf.SplitIdLine(r.head.Pop(shared))
 
-		// Skip spacer line and quality line
-		r.scan.Scan()
-		r.scan.Scan()
+	// Get the sequence line(s)
+	nl := 0
+	for {
+		line, ok = r.next()
+		if !ok {
+			return r.truncated()
+		}
+		if len(line) > 0 {
+			if line[0] == SpPreffix {
+				break
+			}
+			if line[0] == IdPreffix {
+				return r.parseError(errNoSpacer)
+			}
+		}
+		if r.strict && nl == 1 {
+			return r.parseError(errNoSpacer)
+		}
+		err := r.valid.Append(newSeq, line)
+		if err != nil {
+			return r.parseError(err)
+		}
+		nl++
+	}
+	if newSeq.Length() == 0 {
+		return r.parseError(seqitf.ErrEmptySequence)
+	}
 
-		return newSeq, nil
+	// Get (or skip) the quality line(s)
+	// NOTE: quality lines may start with '@' or '+'
+	newSeq.Quality.Phred = r.phred
+	nq := 0
+	nl = 0
+	for nq < newSeq.Length() {
+		if r.strict && nl == 1 {
+			break
+		}
+		line, ok = r.next()
+		if !ok {
+			return r.truncated()
+		}
+		nq += len(line)
+		if r.keepQual {
+			qerr := newSeq.Quality.AppendStrScore(line)
+			if qerr != nil && r.warn == nil {
+				// Quality errors are not fatal, return them as a warning
+				r.warn = r.parseError(qerr)
+			}
+		}
+		nl++
+	}
+	if nq != newSeq.Length() {
+		return r.parseError(errQualLength)
+	}
+	if r.keepQual && r.phred == 0 && r.warn == nil {
+		r.phred = newSeq.Quality.Phred
 	}
 
-	r.eof = true
-	return newSeq, nil
+	r.count++
+	return nil
 }
@@ -0,0 +1,207 @@
+package fastnq
+
+import (
+	"bytes"
+	"errors"
+	"math/rand"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hdevillers/go-seq/seqio/fastq"
+	"github.com/hdevillers/go-seq/seqio/scanner"
+	"github.com/hdevillers/go-seq/seqio/seqitf"
+)
+
+// Read all the records, return the number of records and the first error
+func readAll(r *Reader) (int, error) {
+	n := 0
+	for !r.IsEOF() {
+		s, err := r.Read()
+		if err != nil {
+			return n, err
+		}
+		if s.Length() > 0 {
+			n++
+		}
+	}
+	return n, nil
+}
+
+// Readers of a text (line scanner and buffered versions)
+func testReaders(text string) map[string]*Reader {
+	return map[string]*Reader{
+		"scanner":  NewReader(scanner.NewScanner(strings.NewReader(text))),
+		"buffered": NewBufferedReader(strings.NewReader(text)),
+	}
+}
+
+func TestRead(t *testing.T) {
+	text := "@r1 first read\nACGT\n+\nIIII\n\n@r2\r\nAC\r\nGT\r\n+r2\r\nII\r\nII\r\n@r3\nNNNN\n+\n@+@+"
+	for name, r := range testReaders(text) {
+		r.SetKeepQuality(true)
+		want := []struct{ id, desc, seq, qual string }{
+			{"r1", "first read", "ACGT", "IIII"},
+			{"r2", "", "ACGT", "IIII"},
+			{"r3", "", "NNNN", "@+@+"},
+		}
+		for _, w := range want {
+			s, err := r.Read()
+			if err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+			if s.Id != w.id || s.Desc != w.desc || string(s.Sequence) != w.seq || string(s.Quality.StrScore) != w.qual {
+				t.Errorf("%s: Read = %s %s %s %s, want %v", name, s.Id, s.Desc, s.Sequence, s.Quality.StrScore, w)
+			}
+			if s.Quality.Length() != len(s.Quality.IntScore) || r.Warning() != nil {
+				t.Errorf("%s: IntScore = %v, warning %v", name, s.Quality.IntScore, r.Warning())
+			}
+		}
+		if s, err := r.Read(); err != nil || s.Length() != 0 || !r.IsEOF() {
+			t.Errorf("%s: Read at the end = %s, %v", name, s.Id, err)
+		}
+	}
+}
+
+func TestQuality(t *testing.T) {
+	text := "@a\nACGT\n+\nII#I\n@b\nACGT\n+\nI\x1fhI\n@c\nACGT\n+\nIIII\n"
+	r := NewBufferedReader(strings.NewReader(text))
+	r.SetKeepQuality(true)
+	for i, warn := range []bool{false, true, false} {
+		s, err := r.Read()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if (r.Warning() != nil) != warn || s.Quality.Phred != 33 {
+			t.Errorf("record %d: PHRED %d, warning %v", i+1, s.Quality.Phred, r.Warning())
+		}
+		if i == 0 && (s.Quality.IntScore[0] != 40 || s.Quality.IntScore[2] != 2) {
+			t.Errorf("IntScore = %v", s.Quality.IntScore)
+		}
+	}
+
+	// Skipped quality
+	r = NewBufferedReader(strings.NewReader(text))
+	if s, _ := r.Read(); s.Quality.Length() != 0 || len(s.Quality.IntScore) != 0 {
+		t.Errorf("quality = %q", s.Quality.StrScore)
+	}
+}
+
+func TestParseError(t *testing.T) {
+	tests := []struct {
+		name         string
+		text         string
+		err          error
+		line, record int
+	}{
+		{"misaligned", "@r1\nACGT\n+\nIIII\nACGT\n+\nIIII\n", errNoId, 5, 2},
+		{"extra quality line", "@r1\nACGT\n+\nII\nII\nII\n@r2\nACGT\n+\nIIII\n", errNoId, 6, 2},
+		{"missing spacer", "@r1\nACGT\n+\nIIII\n@r2\nACGT\n@r3\nACGT\n+\nIIII\n", errNoSpacer, 7, 2},
+		{"long quality", "@r1\nACGT\n+\nIIIIII\n", errQualLength, 4, 1},
+		{"truncated after the sequence", "@r1\nACGT\n+\nIIII\n@r2\nACGT\n", errTruncated, 6, 2},
+		{"truncated after the spacer", "@r1\nACGT\n+\nIIII\n@r2\nACGT\n+\n", errTruncated, 7, 2},
+		{"truncated quality", "@r1\nACGT\n+\nIIII\n@r2\nACGT\n+\nII", errTruncated, 8, 2},
+		{"empty sequence", "@r1\n+\n\n", seqitf.ErrEmptySequence, 2, 1},
+	}
+	for _, tt := range tests {
+		for name, r := range testReaders(tt.text) {
+			_, err := readAll(r)
+			var pe *seqitf.ParseError
+			if !errors.As(err, &pe) || !errors.Is(err, tt.err) {
+				t.Errorf("%s, %s: error %v, want %v", tt.name, name, err, tt.err)
+				continue
+			}
+			if pe.Format != "FASTNQ" || pe.Line != tt.line || pe.Record != tt.record {
+				t.Errorf("%s, %s: %s line %d record %d, want line %d record %d", tt.name, name, pe.Format, pe.Line, pe.Record, tt.line, tt.record)
+			}
+		}
+	}
+}
+
+func TestStrict(t *testing.T) {
+	tests := []struct {
+		text         string
+		err          error
+		line, record int
+	}{
+		{"@r1\nACGT\n+\nIIII\n@r2\nAC\nGT\n+\nIIII\n", errNoSpacer, 7, 2},
+		{"@r1\nACGT\n+\nII\nII\n", errQualLength, 4, 1},
+	}
+	for _, tt := range tests {
+		r := NewBufferedReader(strings.NewReader(tt.text))
+		r.SetStrict(true)
+		_, err := readAll(r)
+		var pe *seqitf.ParseError
+		if !errors.As(err, &pe) || !errors.Is(err, tt.err) || pe.Line != tt.line || pe.Record != tt.record {
+			t.Errorf("%q: error %v, want %v (line %d, record %d)", tt.text, err, tt.err, tt.line, tt.record)
+		}
+		if n, err := readAll(NewBufferedReader(strings.NewReader(tt.text))); err != nil {
+			t.Errorf("%q: %d records, %v without strict mode", tt.text, n, err)
+		}
+	}
+}
+
+func TestBufferGrowth(t *testing.T) {
+	// Records larger than the initial buffer
+	long := strings.Repeat("ACGT", BufSize/2)
+	text := "@long\n" + long + "\n+\n" + strings.Repeat("I", len(long)) + "\n@short\nACGT\n+\nIIII\n"
+	n, err := readAll(NewBufferedReader(strings.NewReader(text)))
+	if n != 2 || err != nil {
+		t.Errorf("%d records, %v", n, err)
+	}
+}
+
+var (
+	benchOnce  sync.Once
+	benchFastq []byte
+)
+
+// Multi-MB FASTQ text (150 bp reads)
+func benchData() []byte {
+	benchOnce.Do(func() {
+		rnd := rand.New(rand.NewSource(1))
+		var b bytes.Buffer
+		sq := make([]byte, 150)
+		ql := make([]byte, 150)
+		for i := 0; i < 40000; i++ {
+			for j := range sq {
+				sq[j] = "ACGT"[rnd.Intn(4)]
+				ql[j] = byte('#' + rnd.Intn(40))
+			}
+			b.WriteString("@read_")
+			b.WriteString(strings.Repeat("x", i%10))
+			b.WriteString(" 1:N:0:ACGT\n")
+			b.Write(sq)
+			b.WriteString("\n+\n")
+			b.Write(ql)
+			b.WriteByte('\n')
+		}
+		benchFastq = b.Bytes()
+	})
+	return benchFastq
+}
+
+func BenchmarkFastnqRead(b *testing.B) {
+	data := benchData()
+	b.SetBytes(int64(len(data)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := readAll(NewBufferedReader(bytes.NewReader(data))); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkFastqRead(b *testing.B) {
+	data := benchData()
+	b.SetBytes(int64(len(data)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		r := fastq.NewReader(scanner.NewScanner(bytes.NewReader(data)))
+		for !r.IsEOF() {
+			if _, err := r.Read(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
@@ -3,7 +3,6 @@ package fastq
 import (
 	"errors"
 	"fmt"
-	"os"
 
 	"github.com/hdevillers/go-seq/seq"
 	"github.com/hdevillers/go-seq/seqio/seqitf"
@@ -14,12 +13,25 @@ const (
 	SpPreffix byte = '+'
 )
 
+var (
+	errNoId       = errors.New("Sequence without ID ou bad format")
+	errNoSpacer   = errors.New("Missing '+' spacer line")
+	errSpacerId   = errors.New("The spacer line ID differs from the sequence ID")
+	errQualLength = errors.New("Sequence and quality with different lengths")
+	errTruncated  = errors.New("Truncated record")
+)
+
 // Fastq sequence reader struct
 type Reader struct {
-	scan     seqitf.FileScanner
-	currId   string
-	eof      bool
-	waitQual bool
+	scan   seqitf.FileScanner
+	head   seqitf.HeadBuffer
+	valid  seqitf.Validator
+	eof    bool
+	strict bool
+	warn   error
+	phred  int
+	line   int
+	count  int
 }
 
 // Fastq sequence writer struct
@@ -29,12 +41,20 @@ type Writer struct {
 }
 
 // Generate a new reader
+// NOTE: sequence and quality can be wrapped on several lines
 func NewReader(fs seqitf.FileScanner) *Reader {
 	return &Reader{
-		scan:     fs,
-		currId:   "",
-		eof:      false,
-		waitQual: false,
+		scan: fs,
+		eof:  false,
+	}
+}
+
+// Generate a new reader accepting only four-line records
+func NewStrictReader(fs seqitf.FileScanner) *Reader {
+	return &Reader{
+		scan:   fs,
+		eof:    false,
+		strict: true,
 	}
 }
 
@@ -46,92 +66,150 @@ func NewWriter(fw seqitf.FileWriter) *Writer {
 	}
 }
 
+// Set the check of the sequence characters
+func (r *Reader) SetValidator(v seqitf.Validator) {
+	r.valid = v
+}
+
 // Return true if reachs the end-of-file
 func (r *Reader) IsEOF() bool {
 	return r.eof
 }
 
+// Return the quality warning of the last read sequence (nil if none)
+// NOTE: quality warnings are not fatal (e.g., inconsistent PHRED values)
+func (r *Reader) Warning() error {
+	return r.warn
+}
+
+// Locate an error in the input
+func (r *Reader) parseError(err error) error {
+	return seqitf.NewParseError("FASTQ", r.line, r.count+1, err)
+}
+
+// Scan the next line, return false at the end of the input
+func (r *Reader) next() bool {
+	if r.scan.Scan() {
+		r.line++
+		return true
+	}
+	return false
+}
+
 // Read a single fastq entry
 func (r *Reader) Read() (seq.Seq, error) {
-	// Initialize the new sequence
 	var newSeq seq.Seq
+	err := r.read(&newSeq, false)
+	return newSeq, err
+}
 
-	for r.scan.Scan() {
-		// Check possible scanning error
-		err := r.scan.Err()
-		if err != nil {
-			return newSeq, err
-		}
+// Read a single fastq entry into an existing sequence (memory reuse)
+func (r *Reader) ReadInto(s *seq.Seq) error {
+	s.Reset()
+	return r.read(s, true)
+}
 
-		// Get the scanned line
-		line := r.scan.Bytes()
-
-		if line[0] == IdPreffix {
-			// This is an ID line
-			if r.currId != "" {
-				// Return the current sequence if not nil
-				if newSeq.Length() == 0 {
-					// Empty sequence or bad format
-					return newSeq, errors.New("[FASTQ READER]: Empty sequence or bad format.")
-				}
-
-				// Seq sequence data
-				newSeq.SetId(r.currId)
-
-				// Save the new ID
-				r.currId = string(line[1:]) // Only skip the line preffix
-				r.waitQual = false
-
-				// Return the sequence
-				return newSeq, nil
-			} else {
-				// Save the new ID
-				r.currId = string(line[1:])
-
-				// The sequence object should be empty
-				if newSeq.Length() > 0 {
-					return newSeq, errors.New("[FASTQ READER]: Sequence without ID ou bad format.")
-				}
-
-				// Continue
-			}
-		} else {
-			if line[0] == SpPreffix {
-				// Finished to read sequence line(s)
-				// Start reading the quality
-				r.waitQual = true
-
-				// At that step, newSeq.Length must not be null
-				if newSeq.Length() == 0 {
-					return newSeq, errors.New("[FASTQ READER]: Empty sequence or bad format.")
-				}
-
-				// Continue
-			} else {
-				// Read sequence data or quality data
-				// NOTE: We accept non standard fastq with sequence on multiple lines
-				if r.waitQual {
-					qerr := newSeq.Quality.AppendStrScore(line)
-					// qerr are not fatal, just thow it
-					fmt.Fprintln(os.Stderr, qerr)
-				} else {
-					newSeq.AppendSequence(line)
-				}
-			}
+func (r *Reader) read(newSeq *seq.Seq, shared bool) error {
+	r.warn = nil
+
+	// Look for the ID line (skip empty lines)
+	var line []byte
+	for {
+		if !r.next() {
+			// Scanning is finished
+			r.eof = true
+			return r.scan.Err()
+		}
+		line = r.scan.Bytes()
+		if len(line) > 0 {
+			break
 		}
 	}
-	// Scanning is finished
-	r.eof = true
+	if line[0] != IdPreffix || len(line) == 1 {
+		return r.parseError(errNoId)
+	}
+	r.head.Set(line[1:]) // Only skip the line preffix
+	head := r.head.Pop(shared)
+	newSeq.Id, newSeq.Desc = seqitf.SplitIdLine(head)
 
-	// Set last sequence ID
-	newSeq.SetId(r.currId)
+	// Read the sequence line(s) until the spacer line
+	// NOTE: We accept non standard fastq with sequence on multiple lines
+	nl := 0
+	for {
+		if !r.next() {
+			return r.truncated()
+		}
+		line = r.scan.Bytes()
+		if len(line) > 0 && line[0] == SpPreffix {
+			break
+		}
+		if r.strict && nl == 1 {
+			return r.parseError(errNoSpacer)
+		}
+		if len(line) > 0 && line[0] == IdPreffix {
+			// A new record starts before the spacer line
+			return r.parseError(errNoSpacer)
+		}
+		err := r.valid.Append(newSeq, line)
+		if err != nil {
+			return r.parseError(err)
+		}
+		nl++
+	}
 
+	// At that step, newSeq.Length must not be null
 	if newSeq.Length() == 0 {
-		return newSeq, errors.New("[FASTQ READER]: Last sequence is null.")
+		return r.parseError(seqitf.ErrEmptySequence)
+	}
+
+	// The spacer line may repeat the ID line (or only the ID)
+	if len(line) > 1 {
+		sp := line[1:]
+		if string(sp) != head && string(sp) != newSeq.Id {
+			return r.parseError(errSpacerId)
+		}
+	}
+
+	// Read the quality line(s) until the sequence length is reached
+	// NOTE: quality lines may start with '@' or '+'
+	// NOTE: the PHRED type is determined once per file (the first record
+	// with an unambiguous score range)
+	newSeq.Quality.Phred = r.phred
+	nl = 0
+	for newSeq.Quality.Length() < newSeq.Length() {
+		if r.strict && nl == 1 {
+			break
+		}
+		if !r.next() {
+			return r.truncated()
+		}
+		line = r.scan.Bytes()
+		qerr := newSeq.Quality.AppendStrScore(line)
+		if qerr != nil && r.warn == nil {
+			// Quality errors are not fatal, return them as a warning
+			r.warn = r.parseError(qerr)
+		}
+		nl++
+	}
+	if newSeq.Quality.Length() != newSeq.Length() {
+		return r.parseError(errQualLength)
+	}
+	if r.phred == 0 && r.warn == nil {
+		r.phred = newSeq.Quality.Phred
 	}
 
-	// Return with no error
-	return newSeq, nil
+	r.count++
+	return nil
+}
+
+// Report a record interrupted by the end of the input
+func (r *Reader) truncated() error {
+	r.eof = true
+	err := r.scan.Err()
+	if err != nil {
+		return err
+	}
+	return r.parseError(errTruncated)
 }
 
 func (w *Writer) Write(s seq.Seq) error {
@@ -140,7 +218,7 @@ func (w *Writer) Write(s seq.Seq) error {
 		return errors.New("[FASTQ WRITER]: Missing sequence ID.")
 	}
 	if s.Length() == 0 {
-		return errors.New("[FASTQ WRITER]: Cannot write out empty sequences.")
+		return fmt.Errorf("[FASTQ WRITER]: %w.", seqitf.ErrEmptySequence)
 	}
 	if len(s.Quality.StrScore) == 0 {
 		// If the quality is empty, then generate a fake score
@@ -150,12 +228,18 @@ func (w *Writer) Write(s seq.Seq) error {
 		return errors.New("[FASTQ WRITER]: Sequence and quality with different lengths.")
 	}
 
-	// Add the ID
+	// Add the ID and the description
 	_, err := w.write.Write([]byte{IdPreffix})
 	_, err = w.write.Write([]byte(s.Id))
 	if err != nil {
 		return err
 	}
+	if s.Desc != "" {
+		_, err = w.write.Write([]byte(" " + s.Desc))
+		if err != nil {
+			return err
+		}
+	}
 	_, err = w.write.Write([]byte{'\n'})
 
 	// Add the sequence
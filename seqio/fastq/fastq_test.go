@@ -0,0 +1,184 @@
+package fastq
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/hdevillers/go-seq/seqio/scanner"
+	"github.com/hdevillers/go-seq/seqio/seqitf"
+)
+
+// Read all the records of a fastq text
+func readAll(t *testing.T, r *Reader) []string {
+	t.Helper()
+	var ids []string
+	for !r.IsEOF() {
+		s, err := r.Read()
+		if err != nil {
+			t.Fatalf("record %d: %v", len(ids)+1, err)
+		}
+		if s.Length() > 0 {
+			ids = append(ids, s.Id)
+		}
+	}
+	return ids
+}
+
+func TestReadLongLine(t *testing.T) {
+	// Sequence and quality lines larger than bufio.Scanner's 64 KiB limit
+	long := strings.Repeat("ACGTN", 40000)
+	qual := strings.Repeat("IIII#", 40000)
+	text := "@long\n" + long + "\n+\n" + qual + "\n@short\nACGT\n+\nIIII\n"
+	for _, strict := range []bool{false, true} {
+		fs := scanner.NewScanner(strings.NewReader(text))
+		r := NewReader(fs)
+		if strict {
+			r = NewStrictReader(fs)
+		}
+		s, err := r.Read()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s.Id != "long" || string(s.Sequence) != long || string(s.Quality.StrScore) != qual {
+			t.Errorf("Read = %s (%d bp, %d qualities)", s.Id, s.Length(), s.Quality.Length())
+		}
+		if ids := readAll(t, r); len(ids) != 1 || ids[0] != "short" {
+			t.Errorf("next records = %v, want [short]", ids)
+		}
+	}
+}
+
+func TestPhred(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		phred []int
+	}{
+		{"ambiguous", "@r1\nACGT\n+\nIIII\n@r2\nACGT\n+\nFFFF\n", []int{0, 0}},
+		{"phred 33", "@r1\nACGT\n+\nII#I\n@r2\nACGT\n+\nFFFF\n", []int{33, 33}},
+		{"phred 64", "@r1\nACGT\n+\nIIhI\n@r2\nACGT\n+\nIIII\n", []int{64, 64}},
+		{"late detection", "@r1\nACGT\n+\nIIII\n@r2\nACGT\n+\nII#I\n@r3\nACGT\n+\nIIII\n", []int{0, 33, 33}},
+	}
+	for _, tt := range tests {
+		r := NewReader(scanner.NewScanner(strings.NewReader(tt.text)))
+		for i, phred := range tt.phred {
+			s, err := r.Read()
+			if err != nil {
+				t.Fatalf("%s: %v", tt.name, err)
+			}
+			if r.Warning() != nil {
+				t.Errorf("%s: record %d: warning %v", tt.name, i+1, r.Warning())
+			}
+			if s.Quality.Phred != phred {
+				t.Errorf("%s: record %d: PHRED %d, want %d", tt.name, i+1, s.Quality.Phred, phred)
+			}
+		}
+	}
+
+	// The detected type is kept for the next records
+	r := NewReader(scanner.NewScanner(strings.NewReader("@r1\nACGT\n+\nIIhI\n@r2\nACGT\n+\nIIII\n")))
+	r.Read()
+	s, _ := r.Read()
+	if s.Quality.IntScore[0] != 'I'-64 {
+		t.Errorf("IntScore = %v, want PHRED 64 scores", s.Quality.IntScore)
+	}
+
+	// Inconsistent values
+	r = NewReader(scanner.NewScanner(strings.NewReader("@r1\nACGT\n+\nI#hI\n")))
+	if _, err := r.Read(); err != nil || r.Warning() == nil {
+		t.Errorf("Read = %v, warning %v", err, r.Warning())
+	}
+
+	// Values checked once the type is known (sequential reader)
+	text := "@a\nACGT\n+\nII#I\n@b\nACGT\n+\nI\x1fhI\n@c\nACGT\n+\nIIII\n@d\nACGT\n+\n\xffIII\n"
+	r = NewReader(scanner.NewScanner(strings.NewReader(text)))
+	for i, warn := range []bool{false, true, false, true} {
+		if _, err := r.Read(); err != nil {
+			t.Fatal(err)
+		}
+		if (r.Warning() != nil) != warn {
+			t.Errorf("record %d: warning %v", i+1, r.Warning())
+		}
+		var pe *seqitf.ParseError
+		if warn && (!errors.As(r.Warning(), &pe) || pe.Record != i+1 || pe.Line != 4*(i+1)) {
+			t.Errorf("record %d: warning %v", i+1, r.Warning())
+		}
+	}
+}
+
+func TestRead(t *testing.T) {
+	tests := []struct {
+		name   string
+		text   string
+		strict bool
+		seqs   []string
+	}{
+		{"four-line records", "@r1 desc\nACGT\n+\nIIII\n@r2\nAC\n+r2\nII\n", true, []string{"ACGT", "AC"}},
+		{"wrapped records", "@r1\nAC\nGT\n+\nII\nII\n\n@r2\nAC\n+\nII", false, []string{"ACGT", "AC"}},
+		{"quality lines starting with @ or +", "@r1\nACGT\n+\n@III\n@r2\nACGT\n+\n+III\n", true, []string{"ACGT", "ACGT"}},
+		{"wrapped quality starting with @", "@r1\nACGT\n+\nII\n@I\n@r2\nACGT\n+\nIIII\n", false, []string{"ACGT", "ACGT"}},
+		{"spacer line with the ID line", "@r1 desc\nACGT\n+r1 desc\nIIII\n", true, []string{"ACGT"}},
+	}
+	for _, tt := range tests {
+		fs := scanner.NewScanner(strings.NewReader(tt.text))
+		r := NewReader(fs)
+		if tt.strict {
+			r = NewStrictReader(fs)
+		}
+		var seqs []string
+		for !r.IsEOF() {
+			s, err := r.Read()
+			if err != nil {
+				t.Fatalf("%s: %v", tt.name, err)
+			}
+			if s.Length() > 0 {
+				seqs = append(seqs, string(s.Sequence))
+				if string(s.Quality.StrScore) == "" || s.Quality.Length() != s.Length() {
+					t.Errorf("%s: quality %q", tt.name, s.Quality.StrScore)
+				}
+			}
+		}
+		if strings.Join(seqs, ",") != strings.Join(tt.seqs, ",") {
+			t.Errorf("%s: %v, want %v", tt.name, seqs, tt.seqs)
+		}
+	}
+}
+
+func TestParseError(t *testing.T) {
+	tests := []struct {
+		name         string
+		text         string
+		strict       bool
+		err          error
+		line, record int
+	}{
+		{"strict wrapped sequence", "@r1\nACGT\n+\nIIII\n@r2\nAC\nGT\n+\nIIII\n", true, errNoSpacer, 7, 2},
+		{"strict wrapped quality", "@r1\nACGT\n+\nII\nII\n", true, errQualLength, 4, 1},
+		{"spacer ID mismatch", "@r1\nACGT\n+r1\nIIII\n@r2\nACGT\n+r1\nIIII\n", false, errSpacerId, 7, 2},
+		{"missing spacer", "@r1\nACGT\n@r2\nACGT\n+\nIIII\n", false, errNoSpacer, 3, 1},
+		{"misaligned record", "@r1\nACGT\n+\nIIII\nACGT\n", false, errNoId, 5, 2},
+		{"long quality", "@r1\nACGT\n+\nIIIIII\n", false, errQualLength, 4, 1},
+		{"truncated record", "@r1\nACGT\n+\nIIII\n@r2\nACGT\n+\n", false, errTruncated, 7, 2},
+		{"empty sequence", "@r1\n+\nIIII\n", false, seqitf.ErrEmptySequence, 2, 1},
+	}
+	for _, tt := range tests {
+		fs := scanner.NewScanner(strings.NewReader(tt.text))
+		r := NewReader(fs)
+		if tt.strict {
+			r = NewStrictReader(fs)
+		}
+		var err error
+		for err == nil && !r.IsEOF() {
+			_, err = r.Read()
+		}
+		var pe *seqitf.ParseError
+		if !errors.As(err, &pe) || !errors.Is(err, tt.err) {
+			t.Errorf("%s: error %v, want %v", tt.name, err, tt.err)
+			continue
+		}
+		if pe.Line != tt.line || pe.Record != tt.record {
+			t.Errorf("%s: line %d record %d, want line %d record %d", tt.name, pe.Line, pe.Record, tt.line, tt.record)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package seqio
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/hdevillers/go-seq/seqio/bam"
+	"github.com/hdevillers/go-seq/seqio/bgzf"
+	"github.com/hdevillers/go-seq/seqio/embl"
+	"github.com/hdevillers/go-seq/seqio/fasta"
+	"github.com/hdevillers/go-seq/seqio/fastnq"
+	"github.com/hdevillers/go-seq/seqio/fastq"
+	"github.com/hdevillers/go-seq/seqio/genbank"
+	"github.com/hdevillers/go-seq/seqio/sam"
+	"github.com/hdevillers/go-seq/seqio/scanner"
+	"github.com/hdevillers/go-seq/seqio/seqitf"
+)
+
+// SAM header record types
+var samHeaders = [][]byte{
+	[]byte("@HD\t"),
+	[]byte("@SQ\t"),
+	[]byte("@RG\t"),
+	[]byte("@PG\t"),
+	[]byte("@CO\t"),
+}
+
+// Built-in formats (the order matters for the detection)
+func init() {
+	Register(Format{
+		Name:       "bam",
+		Extensions: []string{".bam"},
+		Sniff:      isBam,
+		Raw:        true,
+		NewReader: func(in io.Reader, o Options) (seqitf.SeqReader, error) {
+			return bam.NewReader(in), nil
+		},
+	})
+	Register(Format{
+		Name:       "sam",
+		Extensions: []string{".sam"},
+		Sniff:      isSam,
+		NewReader: func(in io.Reader, o Options) (seqitf.SeqReader, error) {
+			return sam.NewReader(scanner.NewScanner(in)), nil
+		},
+	})
+	Register(Format{
+		Name:       "fastq",
+		Aliases:    []string{"fq"},
+		Extensions: []string{".fastq", ".fq"},
+		Sniff:      firstByte('@'),
+		NewReader: func(in io.Reader, o Options) (seqitf.SeqReader, error) {
+			if o.Strict {
+				return fastq.NewStrictReader(scanner.NewScanner(in)), nil
+			}
+			return fastq.NewReader(scanner.NewScanner(in)), nil
+		},
+		NewWriter: newFastqWriter,
+	})
+	Register(Format{
+		// Fast fastq parser (never detected)
+		Name:    "fastnq",
+		Aliases: []string{"fnq"},
+		NewReader: func(in io.Reader, o Options) (seqitf.SeqReader, error) {
+			r := fastnq.NewBufferedReader(in)
+			r.SetKeepQuality(o.Quality)
+			r.SetStrict(o.Strict)
+			return r, nil
+		},
+		NewWriter: newFastqWriter,
+	})
+	Register(Format{
+		Name:       "fasta",
+		Aliases:    []string{"fa"},
+		Extensions: []string{".fasta", ".fa", ".fna", ".faa", ".ffn", ".fas"},
+		Sniff:      firstByte('>'),
+		NewReader: func(in io.Reader, o Options) (seqitf.SeqReader, error) {
+			return fasta.NewReader(scanner.NewScanner(in)), nil
+		},
+		NewWriter: func(out seqitf.FileWriter, o Options) (seqitf.SeqWriter, error) {
+			return fasta.NewWriterWithOptions(out, o.Fasta), nil
+		},
+	})
+	Register(Format{
+		Name:       "genbank",
+		Aliases:    []string{"gb"},
+		Extensions: []string{".gb", ".gbk", ".genbank"},
+		Sniff:      prefix("LOCUS "),
+		NewReader: func(in io.Reader, o Options) (seqitf.SeqReader, error) {
+			return genbank.NewReader(scanner.NewScanner(in)), nil
+		},
+		NewWriter: func(out seqitf.FileWriter, o Options) (seqitf.SeqWriter, error) {
+			return genbank.NewWriter(out), nil
+		},
+	})
+	Register(Format{
+		Name:       "embl",
+		Aliases:    []string{"uniprot", "swiss"},
+		Extensions: []string{".embl", ".dat"},
+		Sniff:      prefix("ID   "),
+		NewReader: func(in io.Reader, o Options) (seqitf.SeqReader, error) {
+			return embl.NewReader(scanner.NewScanner(in)), nil
+		},
+		NewWriter: func(out seqitf.FileWriter, o Options) (seqitf.SeqWriter, error) {
+			return embl.NewWriter(out), nil
+		},
+	})
+}
+
+func newFastqWriter(out seqitf.FileWriter, o Options) (seqitf.SeqWriter, error) {
+	return fastq.NewWriter(out), nil
+}
+
+// Skip the leading blank characters of an input
+func trimHead(head []byte) []byte {
+	return bytes.TrimLeft(head, " \t\r\n")
+}
+
+// Sniffer checking the first (non blank) byte
+func firstByte(b byte) func([]byte) bool {
+	return func(head []byte) bool {
+		head = trimHead(head)
+		return len(head) > 0 && head[0] == b
+	}
+}
+
+// Sniffer checking the first (non blank) bytes
+func prefix(p string) func([]byte) bool {
+	return func(head []byte) bool {
+		return bytes.HasPrefix(trimHead(head), []byte(p))
+	}
+}
+
+// Check if the first BGZF block contains a BAM header
+func isBam(head []byte) bool {
+	if !bgzf.IsBgzf(head) {
+		return false
+	}
+	magic := make([]byte, len(bam.Magic))
+	_, err := io.ReadFull(bgzf.NewReader(bytes.NewReader(head)), magic)
+	return err == nil && string(magic) == bam.Magic
+}
+
+// Check if the input starts with a SAM header or an alignment line (at
+// least 11 tab separated fields)
+func isSam(head []byte) bool {
+	head = trimHead(head)
+	if len(head) == 0 {
+		return false
+	}
+	if head[0] == '@' {
+		for _, h := range samHeaders {
+			if bytes.HasPrefix(head, h) {
+				return true
+			}
+		}
+		return false
+	}
+	if head[0] == '>' {
+		return false
+	}
+	line := head
+	if i := bytes.IndexByte(head, '\n'); i >= 0 {
+		line = head[:i]
+	}
+	return bytes.Count(line, []byte{'\t'}) >= 10
+}
@@ -0,0 +1,428 @@
+package genbank
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/hdevillers/go-seq/feature"
+	"github.com/hdevillers/go-seq/seq"
+	"github.com/hdevillers/go-seq/seqio/seqitf"
+)
+
+const (
+	EndOfRecord  string = "//"
+	HeaderIndent int    = 12
+	FeatIndent   int    = 5
+	LineLength   int    = 60
+	BlockLength  int    = 10
+)
+
+const (
+	defaultMolType  = "DNA"
+	defaultTopology = "linear"
+	defaultDivision = "UNK"
+	defaultDate     = "01-JAN-1980"
+)
+
+var reDate = regexp.MustCompile(`^[0-9]{2}-[A-Z]{3}-[0-9]{4}$`)
+
+// LOCUS line content
+type Locus struct {
+	Name     string
+	Length   int
+	Unit     string
+	MolType  string
+	Topology string
+	Division string
+	Date     string
+}
+
+// GenBank record: the sequence (with its features) and header data
+type Record struct {
+	Seq       seq.Seq
+	Locus     Locus
+	Accession []string
+	Version   string
+}
+
+// GenBank sequence reader struct
+type Reader struct {
+	scan  seqitf.FileScanner
+	valid seqitf.Validator
+	eof   bool
+	line  int
+	count int
+}
+
+// GenBank sequence writer struct
+type Writer struct {
+	write seqitf.FileWriter
+	Count int
+}
+
+// Generate a new reader
+func NewReader(fs seqitf.FileScanner) *Reader {
+	return &Reader{
+		scan: fs,
+		eof:  false,
+	}
+}
+
+// Generate a new writer
+func NewWriter(fw seqitf.FileWriter) *Writer {
+	return &Writer{
+		write: fw,
+		Count: 0,
+	}
+}
+
+// Set the check of the sequence characters
+func (r *Reader) SetValidator(v seqitf.Validator) {
+	r.valid = v
+}
+
+// Return true if reachs the end-of-file
+func (r *Reader) IsEOF() bool {
+	return r.eof
+}
+
+// Locate an error in the input
+func (r *Reader) parseError(err error) error {
+	return seqitf.NewParseError("GENBANK", r.line, r.count+1, err)
+}
+
+func parseLocus(l string) (Locus, error) {
+	var locus Locus
+	data := strings.Fields(l)
+	if len(data) < 3 {
+		return locus, errors.New("Malformed LOCUS line")
+	}
+	locus.Name = data[1]
+	n, err := strconv.Atoi(data[2])
+	if err != nil {
+		return locus, errors.New("Invalid sequence length in LOCUS line")
+	}
+	locus.Length = n
+	var fields []string
+	if len(data) > 3 {
+		locus.Unit = data[3]
+		fields = data[4:]
+	}
+	for _, d := range fields {
+		switch {
+		case d == "linear" || d == "circular":
+			locus.Topology = d
+		case reDate.MatchString(d):
+			locus.Date = d
+		case locus.MolType == "" && locus.Topology == "":
+			locus.MolType = d
+		default:
+			locus.Division = d
+		}
+	}
+	return locus, nil
+}
+
+// Read a single GenBank entry with its header data
+func (r *Reader) ReadRecord() (Record, error) {
+	rec, err := r.readRecord()
+	if err != nil {
+		// Scanning errors are returned as is
+		if r.scan.Err() != nil {
+			return rec, err
+		}
+		return rec, r.parseError(err)
+	}
+	if rec.Seq.Id != "" {
+		// Check the sequence characters
+		err = r.valid.Check(&rec.Seq)
+		if err != nil {
+			return rec, r.parseError(err)
+		}
+		r.count++
+	}
+	return rec, nil
+}
+
+func (r *Reader) readRecord() (Record, error) {
+	var rec Record
+	var section string
+	var def []string
+	started := false
+	table := feature.NewTableParser()
+
+	for r.scan.Scan() {
+		// Check possible scanning error
+		err := r.scan.Err()
+		if err != nil {
+			return rec, err
+		}
+
+		// Get the scanned line
+		line := string(r.scan.Bytes())
+		r.line++
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		// End of the record
+		if strings.HasPrefix(line, EndOfRecord) {
+			if !started {
+				return rec, errors.New("End of record without LOCUS line")
+			}
+			rec.Seq.Desc = strings.TrimSuffix(strings.Join(def, " "), ".")
+			rec.Seq.Features, err = table.Features()
+			if err != nil {
+				return rec, err
+			}
+			if rec.Seq.Length() == 0 {
+				return rec, errors.New("Record without sequence (" + rec.Seq.Id + ")")
+			}
+			return rec, nil
+		}
+
+		// Keyword lines start with a non-space character
+		if line[0] != ' ' {
+			fields := strings.Fields(line)
+			section = fields[0]
+			if section != "LOCUS" && !started {
+				return rec, errors.New("Record must start with a LOCUS line")
+			}
+		}
+
+		// Data part of the line (after the keyword)
+		var data string
+		if len(line) > HeaderIndent {
+			data = strings.TrimSpace(line[HeaderIndent:])
+		}
+
+		switch section {
+		case "LOCUS":
+			if started {
+				return rec, errors.New("Missing end of record before LOCUS line")
+			}
+			started = true
+			rec.Locus, err = parseLocus(line)
+			if err != nil {
+				return rec, err
+			}
+			rec.Seq.SetId(rec.Locus.Name)
+		case "DEFINITION":
+			def = append(def, data)
+		case "ACCESSION":
+			rec.Accession = append(rec.Accession, strings.Fields(data)...)
+		case "VERSION":
+			if rec.Version == "" && data != "" {
+				rec.Version = strings.Fields(data)[0]
+			}
+		case "FEATURES":
+			if line[0] == ' ' && len(line) > FeatIndent {
+				err = table.ParseLine(line[FeatIndent:])
+				if err != nil {
+					return rec, err
+				}
+			}
+		case "ORIGIN":
+			if line[0] == ' ' {
+				for _, b := range []byte(line) {
+					if b == ' ' || (b >= '0' && b <= '9') {
+						continue
+					}
+					if b >= 'a' && b <= 'z' {
+						b -= 'a' - 'A'
+					}
+					rec.Seq.Sequence = append(rec.Seq.Sequence, b)
+				}
+			}
+		}
+	}
+	// Scanning is finished
+	r.eof = true
+
+	// Check possible scanning error
+	err := r.scan.Err()
+	if err != nil {
+		return rec, err
+	}
+
+	if started {
+		return rec, errors.New("Truncated record (" + rec.Seq.Id + ")")
+	}
+
+	// Return an empty sequence with no error
+	return rec, nil
+}
+
+// Read a single GenBank entry
+func (r *Reader) Read() (seq.Seq, error) {
+	rec, err := r.ReadRecord()
+	return rec.Seq, err
+}
+
+// Guess the length unit from the sequence content
+func guessUnit(s []byte) string {
+	if seqitf.IsProtein(s) {
+		return "aa"
+	}
+	return "bp"
+}
+
+// Write a header keyword and its wrapped data
+func (w *Writer) writeHeader(key string, data string) error {
+	lines := []string{""}
+	for _, word := range strings.Fields(data) {
+		last := len(lines) - 1
+		if lines[last] == "" {
+			lines[last] = word
+		} else if HeaderIndent+len(lines[last])+1+len(word) <= 79 {
+			lines[last] += " " + word
+		} else {
+			lines = append(lines, word)
+		}
+	}
+	for i, l := range lines {
+		if i == 0 {
+			l = fmt.Sprintf("%-*s%s\n", HeaderIndent, key, l)
+		} else {
+			l = strings.Repeat(" ", HeaderIndent) + l + "\n"
+		}
+		_, err := w.write.Write([]byte(l))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Write a GenBank entry with its header data
+func (w *Writer) WriteRecord(rec Record) error {
+	s := rec.Seq
+	if s.Id == "" && rec.Locus.Name == "" {
+		return errors.New("[GENBANK WRITER]: Missing sequence ID.")
+	}
+	if s.Length() == 0 {
+		return fmt.Errorf("[GENBANK WRITER]: %w.", seqitf.ErrEmptySequence)
+	}
+
+	// Complete the LOCUS data with default values
+	locus := rec.Locus
+	if locus.Name == "" {
+		locus.Name = s.Id
+	}
+	locus.Length = s.Length()
+	if locus.Unit == "" {
+		locus.Unit = guessUnit(s.Sequence)
+	}
+	if locus.MolType == "" && locus.Unit != "aa" {
+		locus.MolType = defaultMolType
+	}
+	if locus.Topology == "" {
+		locus.Topology = defaultTopology
+	}
+	if locus.Division == "" {
+		locus.Division = defaultDivision
+	}
+	if locus.Date == "" {
+		locus.Date = defaultDate
+	}
+	// NOTE: the strandedness (ss-, ds- or ms-) has its own columns
+	strand, molType := "", locus.MolType
+	if len(molType) > 3 && molType[2] == '-' {
+		strand, molType = molType[:3], molType[3:]
+	}
+	_, err := w.write.Write([]byte(fmt.Sprintf("LOCUS       %-16s %11d %s %3s%-6s  %-8s %s %s\n",
+		locus.Name, locus.Length, locus.Unit, strand, molType, locus.Topology, locus.Division, locus.Date)))
+	if err != nil {
+		return err
+	}
+
+	// Header lines
+	def := s.Desc
+	if def == "" {
+		def = "."
+	} else if !strings.HasSuffix(def, ".") {
+		def += "."
+	}
+	accession := rec.Accession
+	if len(accession) == 0 {
+		accession = []string{locus.Name}
+	}
+	version := rec.Version
+	if version == "" {
+		version = accession[0]
+	}
+	header := [][2]string{
+		{"DEFINITION", def},
+		{"ACCESSION", strings.Join(accession, " ")},
+		{"VERSION", version},
+		{"KEYWORDS", "."},
+		{"SOURCE", "."},
+		{"  ORGANISM", "."},
+	}
+	for _, h := range header {
+		err = w.writeHeader(h[0], h[1])
+		if err != nil {
+			return err
+		}
+	}
+
+	// Feature table
+	if len(s.Features) > 0 {
+		_, err = w.write.Write([]byte("FEATURES             Location/Qualifiers\n"))
+		if err != nil {
+			return err
+		}
+		prefix := strings.Repeat(" ", FeatIndent)
+		for _, f := range s.Features {
+			for _, l := range f.Lines(prefix) {
+				_, err = w.write.Write([]byte(l + "\n"))
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	// Sequence (lower case, 6 blocks of 10 residues per line)
+	_, err = w.write.Write([]byte("ORIGIN\n"))
+	if err != nil {
+		return err
+	}
+	lower := bytes.ToLower(s.Sequence)
+	var buf bytes.Buffer
+	for i := 0; i < len(lower); i += LineLength {
+		buf.Reset()
+		buf.WriteString(fmt.Sprintf("%9d", i+1))
+		for j := i; j < i+LineLength && j < len(lower); j += BlockLength {
+			end := j + BlockLength
+			if end > len(lower) {
+				end = len(lower)
+			}
+			buf.WriteByte(' ')
+			buf.Write(lower[j:end])
+		}
+		buf.WriteByte('\n')
+		_, err = w.write.Write(buf.Bytes())
+		if err != nil {
+			return err
+		}
+	}
+	_, err = w.write.Write([]byte(EndOfRecord + "\n"))
+	w.Count++
+
+	return err
+}
+
+// Write a GenBank entry from a sequence
+func (w *Writer) Write(s seq.Seq) error {
+	return w.WriteRecord(Record{Seq: s})
+}
+
+func (w *Writer) Flush() error {
+	err := w.write.Flush()
+	return err
+}
@@ -0,0 +1,211 @@
+package genbank
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/hdevillers/go-seq/seqio/scanner"
+)
+
+// NCBI record layout: NC_001422 header and first 120 bp, the feature table
+// is made to cover the join/complement/order locations and wrapped values
+const ncbiRecord = `LOCUS       NC_001422                120 bp ss-DNA     circular PHG 06-JUL-2018
+DEFINITION  Escherichia phage phiX174, partial genome (test excerpt with a
+            wrapped definition line).
+ACCESSION   NC_001422
+VERSION     NC_001422.1
+KEYWORDS    RefSeq.
+SOURCE      Escherichia phage phiX174
+  ORGANISM  Escherichia phage phiX174
+            Viruses; Monodnaviria; Sangervirae; Phixviricota;
+            Malgrandaviricetes; Petitvirales; Microviridae; Bullavirinae;
+            Sinsheimervirus.
+REFERENCE   1  (bases 1 to 120)
+  AUTHORS   Sanger,F., Air,G.M., Barrell,B.G., Brown,N.L., Coulson,A.R.,
+            Fiddes,C.A., Hutchison,C.A., Slocombe,P.M. and Smith,M.
+  TITLE     Nucleotide sequence of bacteriophage phi X174 DNA
+  JOURNAL   Nature 265 (5596), 687-695 (1977)
+   PUBMED   870828
+FEATURES             Location/Qualifiers
+     source          1..120
+                     /organism="Escherichia phage phiX174"
+                     /mol_type="genomic DNA"
+                     /db_xref="taxon:10847"
+     gene            join(100..120,1..30)
+                     /gene="A"
+                     /locus_tag="phiX174p01"
+     CDS             join(100..120,1..30)
+                     /gene="A"
+                     /codon_start=1
+                     /transl_table=11
+                     /product="replication initiation protein"
+                     /translation="MVRSYYPSECHADYFDFERIEALKPAIEACGISTLSQSPMLGF"
+     CDS             complement(join(5..20,31..45,50..61,70..81,90..98,
+                     101..110))
+                     /note="hypothetical protein used to check that long
+                     qualifier values are joined with a space when they are
+                     continued on the next line"
+                     /codon_start=1
+                     /translation="MSQVTEQSVRFQTALASIKLIQASAVLDLTEDDFDFLTSNKVWI
+                     ATDRSRARRCVEACVYGTLDFVGYPRFPAPVEFIAAVIAYYVHPVNIQTACLIMEGAE
+                     FTENIINGVERPVKAAELFAFTLRVRAGNTDVLTDAEENVRQKLRAEGVM"
+     misc_feature    order(10..12,40..42)
+                     /note="origin (order location)"
+ORIGIN
+        1 gagttttatc gcttccatga cgcagaagtt aacactttcg gatatttctg atgagtcgaa
+       61 aaattatctt gataaagcag gaattactac tgcttgttta cgaattaaat cgaagtggac
+//
+`
+
+func readRecord(t *testing.T, text string) Record {
+	t.Helper()
+	r := NewReader(scanner.NewScanner(strings.NewReader(text)))
+	rec, err := r.ReadRecord()
+	if err != nil {
+		t.Fatalf("ReadRecord: %v", err)
+	}
+	return rec
+}
+
+func writeRecord(t *testing.T, rec Record) string {
+	t.Helper()
+	var buf bytes.Buffer
+	w := NewWriter(bufio.NewWriter(&buf))
+	err := w.WriteRecord(rec)
+	if err == nil {
+		err = w.Flush()
+	}
+	if err != nil {
+		t.Fatalf("WriteRecord: %v", err)
+	}
+	return buf.String()
+}
+
+// Get the lines from the first line starting with prefix to the end
+func linesFrom(text, prefix string) string {
+	i := strings.Index(text, "\n"+prefix)
+	if i < 0 {
+		return ""
+	}
+	return text[i+1:]
+}
+
+func TestReadRecord(t *testing.T) {
+	rec := readRecord(t, ncbiRecord)
+
+	locus := Locus{"NC_001422", 120, "bp", "ss-DNA", "circular", "PHG", "06-JUL-2018"}
+	if rec.Locus != locus {
+		t.Errorf("Locus = %+v, want %+v", rec.Locus, locus)
+	}
+	if rec.Seq.Id != "NC_001422" || rec.Version != "NC_001422.1" {
+		t.Errorf("Id, Version = %q, %q", rec.Seq.Id, rec.Version)
+	}
+	desc := "Escherichia phage phiX174, partial genome (test excerpt with a wrapped definition line)"
+	if rec.Seq.Desc != desc {
+		t.Errorf("Desc = %q, want %q", rec.Seq.Desc, desc)
+	}
+	if rec.Seq.Length() != 120 || string(rec.Seq.Sequence[:10]) != "GAGTTTTATC" || string(rec.Seq.Sequence[110:]) != "CGAAGTGGAC" {
+		t.Errorf("Sequence = %q (%d)", rec.Seq.Sequence, rec.Seq.Length())
+	}
+
+	locations := []struct{ key, loc string }{
+		{"source", "1..120"},
+		{"gene", "join(100..120,1..30)"},
+		{"CDS", "join(100..120,1..30)"},
+		{"CDS", "complement(join(5..20,31..45,50..61,70..81,90..98,101..110))"},
+		{"misc_feature", "order(10..12,40..42)"},
+	}
+	if len(rec.Seq.Features) != len(locations) {
+		t.Fatalf("%d features, want %d", len(rec.Seq.Features), len(locations))
+	}
+	for i, l := range locations {
+		f := rec.Seq.Features[i]
+		if f.Key != l.key || f.Location != l.loc {
+			t.Errorf("feature %d = %s %s, want %s %s", i, f.Key, f.Location, l.key, l.loc)
+		}
+	}
+
+	// Multi-line qualifiers: words are joined with a space, translations
+	// without separator
+	cds := rec.Seq.Features[3]
+	note, _ := cds.Value("note")
+	want := "hypothetical protein used to check that long qualifier values are joined with a space when they are continued on the next line"
+	if note != want {
+		t.Errorf("note = %q, want %q", note, want)
+	}
+	prot, _ := cds.Value("translation")
+	if len(prot) != 152 || strings.ContainsAny(prot, " \"") {
+		t.Errorf("translation = %q (%d)", prot, len(prot))
+	}
+	if v, _ := cds.Value("codon_start"); v != "1" {
+		t.Errorf("codon_start = %q", v)
+	}
+}
+
+func TestLocusLine(t *testing.T) {
+	lines := []string{
+		"LOCUS       NC_001422                120 bp ss-DNA     circular PHG 06-JUL-2018",
+		"LOCUS       SCU49845                 120 bp    DNA     linear   PLN 21-JUN-1999",
+		"LOCUS       AB000001                 120 bp    mRNA    linear   PRI 14-FEB-2002",
+		"LOCUS       NP_000509                120 aa            linear   PRI 23-MAR-2023",
+	}
+	origin := linesFrom(ncbiRecord, "ORIGIN")
+	for _, l := range lines {
+		rec := readRecord(t, l+"\n"+origin)
+		got := strings.SplitN(writeRecord(t, rec), "\n", 2)[0]
+		if got != l {
+			t.Errorf("LOCUS line:\n got %q\nwant %q", got, l)
+		}
+		if len(got) != 79 {
+			t.Errorf("LOCUS line length = %d, want 79", len(got))
+		}
+	}
+}
+
+func TestShortLocusLine(t *testing.T) {
+	origin := linesFrom(ncbiRecord, "ORIGIN")
+	for _, l := range []string{"LOCUS       x 120", "LOCUS       x 120 bp"} {
+		rec := readRecord(t, l+"\n"+origin)
+		if rec.Locus.Name != "x" || rec.Locus.Length != 120 || rec.Seq.Length() != 120 {
+			t.Errorf("%q: Locus = %+v", l, rec.Locus)
+		}
+	}
+
+	// Missing sequence length
+	r := NewReader(scanner.NewScanner(strings.NewReader("LOCUS       x\n" + origin)))
+	if _, err := r.ReadRecord(); err == nil {
+		t.Errorf("no error with a LOCUS line without length")
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	out := writeRecord(t, readRecord(t, ncbiRecord))
+
+	// LOCUS, DEFINITION, ACCESSION and VERSION lines are kept as is
+	in := strings.Split(ncbiRecord, "\n")
+	got := strings.Split(out, "\n")
+	for i := 0; i < 5; i++ {
+		if got[i] != in[i] {
+			t.Errorf("line %d:\n got %q\nwant %q", i+1, got[i], in[i])
+		}
+	}
+
+	// The feature table and the sequence are written as NCBI does
+	if linesFrom(out, "FEATURES") != linesFrom(ncbiRecord, "FEATURES") {
+		t.Errorf("feature table and sequence differ:\n%s", linesFrom(out, "FEATURES"))
+	}
+
+	// Read the output back
+	rec := readRecord(t, out)
+	ref := readRecord(t, ncbiRecord)
+	if string(rec.Seq.Sequence) != string(ref.Seq.Sequence) || len(rec.Seq.Features) != len(ref.Seq.Features) {
+		t.Fatalf("round trip changed the record")
+	}
+	for i := range rec.Seq.Features {
+		if rec.Seq.Features[i].Location != ref.Seq.Features[i].Location {
+			t.Errorf("feature %d location = %q, want %q", i, rec.Seq.Features[i].Location, ref.Seq.Features[i].Location)
+		}
+	}
+}
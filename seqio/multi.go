@@ -0,0 +1,84 @@
+package seqio
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+)
+
+// Files remaining to read (multi-file readers)
+type multiFiles struct {
+	files  []string
+	format string
+	opts   Options
+}
+
+// Open several sequence files read as a single one
+// NOTE: files are read in the given order, the compression and the format
+// (with "auto") are detected for each file, Source returns the file of the
+// current sequence
+func OpenMulti(files []string, format string, opts ...Option) (*Reader, error) {
+	if len(files) == 0 {
+		return nil, errors.New("[SEQIO READER]: No input file.")
+	}
+	r, err := Open(files[0], format, opts...)
+	if err != nil {
+		return nil, err
+	}
+	r.multi = &multiFiles{
+		files:  files[1:],
+		format: format,
+		opts:   newOptions(AutoCompression, opts),
+	}
+	return r, nil
+}
+
+// Open the sequence files matching a pattern (see filepath.Match) read as a
+// single one
+// NOTE: files are read in lexical order
+func OpenGlob(pattern string, format string, opts ...Option) (*Reader, error) {
+	files, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("[SEQIO READER]: No file matches the pattern (%s).", pattern)
+	}
+	return OpenMulti(files, format, opts...)
+}
+
+// Switch to the next file, return false if there is no more file
+func (r *Reader) nextFile() bool {
+	if r.multi == nil || len(r.multi.files) == 0 {
+		return false
+	}
+	file := r.multi.files[0]
+	r.multi.files = r.multi.files[1:]
+
+	// Close the current file
+	err := r.fcloser.Close()
+	r.fcloser = nil
+	r.sreader = nil
+	r.reuser = nil
+	if err != nil {
+		r.err = err
+		return false
+	}
+
+	f, err := openFile(file)
+	if err != nil {
+		r.err = err
+		return false
+	}
+	nr := newReader(f, f, r.multi.format, r.multi.opts)
+	if nr.err != nil {
+		r.err = nr.err
+		return false
+	}
+	r.fcloser = nr.fcloser
+	r.sreader = nr.sreader
+	r.reuser = nr.reuser
+	r.format = nr.format
+	r.source = file
+	return true
+}
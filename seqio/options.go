@@ -0,0 +1,115 @@
+package seqio
+
+import (
+	"runtime"
+
+	"github.com/hdevillers/go-seq/alphabet"
+	"github.com/hdevillers/go-seq/seqio/fasta"
+)
+
+const (
+	defaultBatchSize = 4 << 20
+)
+
+// Settings of the readers and writers (set by the Option functions and
+// given to the format constructors, see Format)
+type Options struct {
+	Compression Compression
+	Threads     int
+	BatchSize   int
+	Reuse       bool
+	Strict      bool
+	Quality     bool
+	Fasta       fasta.WriterOptions
+	Alphabet    *alphabet.Alphabet
+	Replace     byte
+}
+
+type Option func(*Options)
+
+// Apply the options over the default settings
+func newOptions(compression Compression, opts []Option) Options {
+	o := Options{
+		Compression: compression,
+		Threads:     runtime.NumCPU(),
+		BatchSize:   defaultBatchSize,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// Set the compression type
+func WithCompression(c Compression) Option {
+	return func(o *Options) {
+		o.Compression = c
+	}
+}
+
+// Set the number of parsing goroutines (batch reader)
+func WithThreads(n int) Option {
+	return func(o *Options) {
+		if n > 0 {
+			o.Threads = n
+		}
+	}
+}
+
+// Set the approximate size in bytes of the parsed chunks (batch reader)
+func WithBatchSize(n int) Option {
+	return func(o *Options) {
+		if n > 0 {
+			o.BatchSize = n
+		}
+	}
+}
+
+// Reuse the memory of the current sequence at each call of Next
+// NOTE: only supported by the fasta, fastq and fastnq readers (ignored with
+// the other formats), the sequence returned by Seq is overwritten by the next
+// call of Next and must be copied to be kept (see seq.Seq.Copy); this
+// includes Id and Desc, which share the reader buffer: copy them to keep
+// them (e.g., as map keys)
+func WithReuse() Option {
+	return func(o *Options) {
+		o.Reuse = true
+	}
+}
+
+// Only accept four-line records in fastq files (fastq and fastnq formats)
+func WithStrict() Option {
+	return func(o *Options) {
+		o.Strict = true
+	}
+}
+
+// Keep the quality with the fastnq format (skipped by default)
+func WithQuality() Option {
+	return func(o *Options) {
+		o.Quality = true
+	}
+}
+
+// Set the fasta writer options (line width, case, header template)
+func WithFastaOptions(opt fasta.WriterOptions) Option {
+	return func(o *Options) {
+		o.Fasta = opt
+	}
+}
+
+// Check the sequence characters against an alphabet (the readers reject
+// the characters out of alphabet.Any by default)
+func WithAlphabet(a *alphabet.Alphabet) Option {
+	return func(o *Options) {
+		o.Alphabet = a
+	}
+}
+
+// Replace the invalid sequence characters (e.g., by 'N') instead of
+// returning an error
+func WithReplace(b byte) Option {
+	return func(o *Options) {
+		o.Replace = b
+	}
+}
@@ -0,0 +1,234 @@
+package seqio
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hdevillers/go-seq/seq"
+)
+
+// Paired-end errors
+var (
+	ErrMateMismatch = errors.New("Mate IDs do not match")
+	ErrMissingMate  = errors.New("Missing mate")
+)
+
+// Paired-end reader structure
+// NOTE: mates are read from two synchronized readers (R1/R2 files) or from
+// a single interleaved reader
+type PairedReader struct {
+	r1    *Reader
+	r2    *Reader
+	s1    seq.Seq
+	s2    seq.Seq
+	count int
+	err   error
+}
+
+// Paired-end writer structure
+type PairedWriter struct {
+	w1 *Writer
+	w2 *Writer
+}
+
+// Open R1/R2 sequence files
+func OpenPaired(file1, file2 string, format string, opts ...Option) (*PairedReader, error) {
+	r1, err := Open(file1, format, opts...)
+	if err != nil {
+		return nil, err
+	}
+	r2, err := Open(file2, format, opts...)
+	if err != nil {
+		r1.Close()
+		return nil, err
+	}
+	return NewPairedReader(r1, r2), nil
+}
+
+// Open an interleaved sequence file
+// NOTE: the WithReuse option is ignored (both mates must be kept)
+func OpenInterleaved(file string, format string, opts ...Option) (*PairedReader, error) {
+	r, err := Open(file, format, opts...)
+	if err != nil {
+		return nil, err
+	}
+	r.reuser = nil
+	return NewPairedReader(r, nil), nil
+}
+
+// Create a paired-end reader from two readers (r2 is nil if r1 is
+// interleaved)
+func NewPairedReader(r1, r2 *Reader) *PairedReader {
+	return &PairedReader{
+		r1: r1,
+		r2: r2,
+	}
+}
+
+// Return the read name shared by the two mates and the mate number (0 if
+// unknown)
+// NOTE: the mate number is given by the /1 or /2 suffix (removed) or by the
+// Casava 1.8 comment (e.g., "1:N:0:ATCACG")
+func mateName(id string) (string, int) {
+	mate := 0
+	if i := strings.IndexAny(id, " \t"); i >= 0 {
+		c := strings.TrimLeft(id[i:], " \t")
+		if len(c) > 3 && (c[0] == '1' || c[0] == '2') && c[1] == ':' && (c[2] == 'Y' || c[2] == 'N') && c[3] == ':' {
+			mate = int(c[0] - '0')
+		}
+		id = id[:i]
+	}
+	if n := len(id); n > 2 && id[n-2] == '/' && (id[n-1] == '1' || id[n-1] == '2') {
+		mate = int(id[n-1] - '0')
+		id = id[:n-2]
+	}
+	return id, mate
+}
+
+// Return true if the two IDs are the IDs of mates
+// NOTE: the IDs may include the comment, if a mate number is given (/1 or
+// /2 suffix, Casava comment), id1 must be the mate 1 and id2 the mate 2
+func IsMate(id1, id2 string) bool {
+	n1, m1 := mateName(id1)
+	n2, m2 := mateName(id2)
+	if n1 != n2 {
+		return false
+	}
+	return (m1 == 0 && m2 == 0) || (m1 == 1 && m2 == 2)
+}
+
+// Return the ID line of a sequence (ID and description)
+func idLine(s seq.Seq) string {
+	if s.Desc == "" {
+		return s.Id
+	}
+	return s.Id + " " + s.Desc
+}
+
+// Read the next sequence of a mate reader
+func (p *PairedReader) nextMate(r *Reader, mate int) (seq.Seq, bool) {
+	if !r.Next() {
+		p.err = r.Err()
+		if p.err == nil && mate == 2 {
+			p.err = fmt.Errorf("[SEQIO PAIRED READER]: %w (R2 of pair %d).", ErrMissingMate, p.count+1)
+		}
+		return seq.Seq{}, false
+	}
+	if r.Err() != nil {
+		p.err = r.Err()
+		return seq.Seq{}, false
+	}
+	return r.Seq(), true
+}
+
+// Read the next pair
+func (p *PairedReader) Next() bool {
+	if p.err != nil {
+		return false
+	}
+	var ok bool
+	p.s1, ok = p.nextMate(p.r1, 1)
+	if !ok {
+		// The R2 file must be finished too
+		if p.err == nil && p.r2 != nil && p.r2.Next() {
+			p.err = fmt.Errorf("[SEQIO PAIRED READER]: %w (R1 of pair %d).", ErrMissingMate, p.count+1)
+		}
+		return false
+	}
+	r2 := p.r2
+	if r2 == nil {
+		r2 = p.r1
+	}
+	p.s2, ok = p.nextMate(r2, 2)
+	if !ok {
+		return false
+	}
+	if !IsMate(idLine(p.s1), idLine(p.s2)) {
+		p.err = fmt.Errorf("[SEQIO PAIRED READER]: %w (pair %d: %s, %s).", ErrMateMismatch, p.count+1, p.s1.Id, p.s2.Id)
+		return false
+	}
+	p.count++
+	return true
+}
+
+// Get the current pair
+func (p *PairedReader) Pair() (seq.Seq, seq.Seq) {
+	return p.s1, p.s2
+}
+
+// Get the format of the input
+func (p *PairedReader) Format() string {
+	return p.r1.Format()
+}
+
+// Get the last error
+func (p *PairedReader) Err() error {
+	return p.err
+}
+
+// Close file handles
+func (p *PairedReader) Close() error {
+	err := p.r1.Close()
+	if p.r2 != nil {
+		if err2 := p.r2.Close(); err == nil {
+			err = err2
+		}
+	}
+	return err
+}
+
+// Create R1/R2 sequence files
+func CreatePaired(file1, file2 string, format string, opts ...Option) (*PairedWriter, error) {
+	w1, err := Create(file1, format, opts...)
+	if err != nil {
+		return nil, err
+	}
+	w2, err := Create(file2, format, opts...)
+	if err != nil {
+		w1.Close()
+		return nil, err
+	}
+	return NewPairedWriter(w1, w2), nil
+}
+
+// Create an interleaved sequence file
+func CreateInterleaved(file string, format string, opts ...Option) (*PairedWriter, error) {
+	w, err := Create(file, format, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return NewPairedWriter(w, nil), nil
+}
+
+// Create a paired-end writer from two writers (w2 is nil to interleave the
+// mates in w1)
+func NewPairedWriter(w1, w2 *Writer) *PairedWriter {
+	return &PairedWriter{
+		w1: w1,
+		w2: w2,
+	}
+}
+
+// Write a pair
+func (p *PairedWriter) Write(s1, s2 seq.Seq) error {
+	err := p.w1.Write(s1)
+	if err != nil {
+		return err
+	}
+	if p.w2 == nil {
+		return p.w1.Write(s2)
+	}
+	return p.w2.Write(s2)
+}
+
+// Close output files
+func (p *PairedWriter) Close() error {
+	err := p.w1.Close()
+	if p.w2 != nil {
+		if err2 := p.w2.Close(); err == nil {
+			err = err2
+		}
+	}
+	return err
+}
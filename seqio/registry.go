@@ -0,0 +1,125 @@
+package seqio
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/hdevillers/go-seq/seqio/seqitf"
+)
+
+/*
+	Registry of the sequence formats supported by seqio. Packages can
+	plug their own formats by calling Register (usually in an init
+	function), they are then usable with any seqio reader or writer.
+*/
+
+// Sequence format description
+type Format struct {
+	// Format name and alternative names (lower case)
+	Name    string
+	Aliases []string
+	// File extensions (e.g., ".fa"), used to select the format of the
+	// outputs created with the "auto" format
+	Extensions []string
+	// Return true if the first bytes of an input are in this format (nil if
+	// the format cannot be detected)
+	Sniff func(head []byte) bool
+	// Raw formats handle their own compression (e.g., BAM), the reader
+	// receives the input as it is and Sniff the compressed bytes
+	Raw bool
+	// Constructors of the parser and of the formatter (nil if not
+	// supported)
+	NewReader func(in io.Reader, o Options) (seqitf.SeqReader, error)
+	NewWriter func(out seqitf.FileWriter, o Options) (seqitf.SeqWriter, error)
+}
+
+var registry = struct {
+	sync.RWMutex
+	formats []*Format
+	names   map[string]*Format
+}{
+	names: make(map[string]*Format),
+}
+
+// Add a format to the registry
+// NOTE: Register panics if a name or an alias is already used, formats are
+// sniffed in their registration order
+func Register(f Format) {
+	registry.Lock()
+	defer registry.Unlock()
+
+	if f.Name == "" {
+		panic("[SEQIO REGISTRY]: Missing format name.")
+	}
+	// Check all the names before registering any of them
+	nf := &f
+	names := make(map[string]*Format)
+	for _, name := range append([]string{f.Name}, f.Aliases...) {
+		name = strings.ToLower(name)
+		if name == "auto" || registry.names[name] != nil || names[name] != nil {
+			panic(fmt.Sprintf("[SEQIO REGISTRY]: Format name already used (%s).", name))
+		}
+		names[name] = nf
+	}
+	for name := range names {
+		registry.names[name] = nf
+	}
+	registry.formats = append(registry.formats, nf)
+}
+
+// Get a format from its name or one of its aliases
+func LookupFormat(name string) (*Format, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+	f, ok := registry.names[strings.ToLower(name)]
+	return f, ok
+}
+
+// List the registered format names
+func Formats() []string {
+	registry.RLock()
+	defer registry.RUnlock()
+	names := make([]string, len(registry.formats))
+	for i, f := range registry.formats {
+		names[i] = f.Name
+	}
+	return names
+}
+
+// Select the format from the file extension (the compression extension is
+// skipped), return an empty string if the format is unknown
+func FormatFromExtension(file string) string {
+	if CompressionFromExtension(file) != NoCompression {
+		file = strings.TrimSuffix(file, filepath.Ext(file))
+	}
+	ext := strings.ToLower(filepath.Ext(file))
+	if ext == "" {
+		return ""
+	}
+
+	registry.RLock()
+	defer registry.RUnlock()
+	for _, f := range registry.formats {
+		for _, e := range f.Extensions {
+			if e == ext {
+				return f.Name
+			}
+		}
+	}
+	return ""
+}
+
+// Detect the format from the first bytes of an input
+func sniffFormat(head []byte, raw bool) *Format {
+	registry.RLock()
+	defer registry.RUnlock()
+	for _, f := range registry.formats {
+		if f.Raw == raw && f.Sniff != nil && f.Sniff(head) {
+			return f
+		}
+	}
+	return nil
+}
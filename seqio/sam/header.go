@@ -0,0 +1,107 @@
+package sam
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// A TAG:VALUE field of a header line
+type HeaderField struct {
+	Tag   string
+	Value string
+}
+
+// A header line (@HD, @SQ, @RG, @PG or any other record type)
+type HeaderLine struct {
+	Type   string
+	Fields []HeaderField
+}
+
+// Reference sequence (@SQ line)
+type Reference struct {
+	Name   string
+	Length int
+}
+
+// SAM header
+type Header struct {
+	Version    string
+	SortOrder  string
+	References []Reference
+	ReadGroups []HeaderLine
+	Programs   []HeaderLine
+	Comments   []string
+	Lines      []HeaderLine
+}
+
+// Get the value of a header line field
+func (h *HeaderLine) Get(tag string) (string, bool) {
+	for _, f := range h.Fields {
+		if f.Tag == tag {
+			return f.Value, true
+		}
+	}
+	return "", false
+}
+
+// Parse a header line and add it to the header
+func (h *Header) parseLine(line string) error {
+	data := strings.Split(line, "\t")
+	if len(data[0]) != 3 || data[0][0] != '@' {
+		return errors.New("Malformed header line (" + line + ")")
+	}
+	hl := HeaderLine{Type: data[0][1:]}
+
+	// Comment lines are not made of fields
+	if hl.Type == "CO" {
+		h.Comments = append(h.Comments, strings.TrimPrefix(line[3:], "\t"))
+		return nil
+	}
+
+	for _, d := range data[1:] {
+		if len(d) < 3 || d[2] != ':' {
+			return errors.New("Malformed header field (" + d + ")")
+		}
+		hl.Fields = append(hl.Fields, HeaderField{Tag: d[:2], Value: d[3:]})
+	}
+	h.Lines = append(h.Lines, hl)
+
+	switch hl.Type {
+	case "HD":
+		h.Version, _ = hl.Get("VN")
+		h.SortOrder, _ = hl.Get("SO")
+	case "SQ":
+		name, ok := hl.Get("SN")
+		if !ok {
+			return errors.New("@SQ line without SN field")
+		}
+		ln, _ := hl.Get("LN")
+		n, err := strconv.Atoi(ln)
+		if err != nil {
+			return errors.New("Invalid reference length (" + name + ")")
+		}
+		h.References = append(h.References, Reference{Name: name, Length: n})
+	case "RG":
+		h.ReadGroups = append(h.ReadGroups, hl)
+	case "PG":
+		h.Programs = append(h.Programs, hl)
+	}
+	return nil
+}
+
+// Parse a whole header text (as found in BAM files)
+func ParseHeader(text string) (Header, error) {
+	var h Header
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimRight(line, "\r\x00")
+		if line == "" {
+			continue
+		}
+		err := h.parseLine(line)
+		if err != nil {
+			return h, err
+		}
+	}
+	return h, nil
+}
@@ -0,0 +1,120 @@
+package sam
+
+import (
+	"bytes"
+	"strconv"
+
+	"github.com/hdevillers/go-seq/seq"
+)
+
+// Alignment flags
+const (
+	FlagPaired        uint16 = 0x1
+	FlagProperPair    uint16 = 0x2
+	FlagUnmapped      uint16 = 0x4
+	FlagMateUnmapped  uint16 = 0x8
+	FlagReverse       uint16 = 0x10
+	FlagMateReverse   uint16 = 0x20
+	FlagRead1         uint16 = 0x40
+	FlagRead2         uint16 = 0x80
+	FlagSecondary     uint16 = 0x100
+	FlagQCFail        uint16 = 0x200
+	FlagDuplicate     uint16 = 0x400
+	FlagSupplementary uint16 = 0x800
+)
+
+// CIGAR operations (in BAM numerical order)
+const CigarOps string = "MIDNSHP=X"
+
+// A single CIGAR operation
+type CigarOp struct {
+	Op  byte
+	Len int
+}
+
+type Cigar []CigarOp
+
+// Optional field (TAG:TYPE:VALUE)
+// NOTE: Value is a string (A, Z, H), an int (i), a float64 (f)
+// or a slice of int or float64 (B, the subtype is kept in SubType)
+type Tag struct {
+	Name    string
+	Type    byte
+	SubType byte
+	Value   interface{}
+}
+
+// Alignment record (the mandatory fields and the optional tags)
+// NOTE: Pos and PNext are 1-based (0 means unavailable), Qual keeps the
+// Phred+33 string and is nil if not available
+type Record struct {
+	QName string
+	Flag  uint16
+	RName string
+	Pos   int
+	MapQ  int
+	Cigar Cigar
+	RNext string
+	PNext int
+	TLen  int
+	Seq   []byte
+	Qual  []byte
+	Tags  []Tag
+}
+
+// Return the CIGAR string
+func (c Cigar) String() string {
+	if len(c) == 0 {
+		return "*"
+	}
+	var buf bytes.Buffer
+	for _, op := range c {
+		buf.WriteString(strconv.Itoa(op.Len))
+		buf.WriteByte(op.Op)
+	}
+	return buf.String()
+}
+
+// Number of reference bases covered by the alignment
+func (c Cigar) RefLength() int {
+	n := 0
+	for _, op := range c {
+		switch op.Op {
+		case 'M', 'D', 'N', '=', 'X':
+			n += op.Len
+		}
+	}
+	return n
+}
+
+// Check a flag value
+func (a *Record) HasFlag(f uint16) bool {
+	return a.Flag&f != 0
+}
+
+// Get an optional field from its name
+func (a *Record) Tag(name string) (Tag, bool) {
+	for _, t := range a.Tags {
+		if t.Name == name {
+			return t, true
+		}
+	}
+	return Tag{}, false
+}
+
+// Convert the alignment into a read as sequenced
+// NOTE: reads aligned on the reverse strand are reverse-complemented
+func (a *Record) ToSeq() seq.Seq {
+	s := *seq.NewSeq(a.QName)
+	n := len(a.Seq)
+	s.Sequence = make([]byte, n)
+	copy(s.Sequence, a.Seq)
+	if a.Qual != nil {
+		s.Quality.Phred = 33
+		s.Quality.AppendStrScore(a.Qual)
+	}
+	if a.HasFlag(FlagReverse) {
+		s.ReverseComplement()
+	}
+	return s
+}
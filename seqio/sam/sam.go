@@ -0,0 +1,265 @@
+package sam
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/hdevillers/go-seq/seq"
+	"github.com/hdevillers/go-seq/seqio/seqitf"
+)
+
+const (
+	HeaderPreffix byte = '@'
+	nFields       int  = 11
+)
+
+// SAM alignment reader struct
+type Reader struct {
+	scan    seqitf.FileScanner
+	header  Header
+	pending string
+	started bool
+	eof     bool
+	line    int
+	count   int
+}
+
+// Generate a new reader
+func NewReader(fs seqitf.FileScanner) *Reader {
+	return &Reader{
+		scan:    fs,
+		started: false,
+		eof:     false,
+	}
+}
+
+// Return true if reachs the end-of-file
+func (r *Reader) IsEOF() bool {
+	return r.eof
+}
+
+// Locate an error in the input
+func (r *Reader) parseError(err error) error {
+	return seqitf.NewParseError("SAM", r.line, r.count+1, err)
+}
+
+// Parse the header lines (up to the first alignment line)
+func (r *Reader) readHeader() error {
+	r.started = true
+	for r.scan.Scan() {
+		line := string(r.scan.Bytes())
+		r.line++
+		if len(line) == 0 {
+			continue
+		}
+		if line[0] != HeaderPreffix {
+			// First alignment line, keep it for the next read
+			r.pending = line
+			return nil
+		}
+		err := r.header.parseLine(line)
+		if err != nil {
+			return r.parseError(err)
+		}
+	}
+	r.eof = true
+	return r.scan.Err()
+}
+
+// Get the SAM header
+func (r *Reader) Header() (Header, error) {
+	if !r.started {
+		err := r.readHeader()
+		if err != nil {
+			return r.header, err
+		}
+	}
+	return r.header, nil
+}
+
+// Parse a CIGAR string
+func parseCigar(s string) (Cigar, error) {
+	var c Cigar
+	if s == "*" {
+		return c, nil
+	}
+	n := 0
+	digits := false
+	for i := 0; i < len(s); i++ {
+		b := s[i]
+		if b >= '0' && b <= '9' {
+			n = n*10 + int(b-'0')
+			digits = true
+			continue
+		}
+		if !digits || strings.IndexByte(CigarOps, b) < 0 {
+			return c, errors.New("Invalid CIGAR string (" + s + ")")
+		}
+		c = append(c, CigarOp{Op: b, Len: n})
+		n = 0
+		digits = false
+	}
+	if digits {
+		return c, errors.New("Invalid CIGAR string (" + s + ")")
+	}
+	return c, nil
+}
+
+// Parse an optional field
+func parseTag(s string) (Tag, error) {
+	var t Tag
+	if len(s) < 5 || s[2] != ':' || s[4] != ':' {
+		return t, errors.New("Malformed optional field (" + s + ")")
+	}
+	t.Name = s[:2]
+	t.Type = s[3]
+	v := s[5:]
+	var err error
+	switch t.Type {
+	case 'A', 'Z', 'H':
+		t.Value = v
+	case 'i':
+		t.Value, err = strconv.Atoi(v)
+	case 'f':
+		t.Value, err = strconv.ParseFloat(v, 64)
+	case 'B':
+		data := strings.Split(v, ",")
+		if len(data[0]) != 1 {
+			return t, errors.New("Invalid array subtype (" + s + ")")
+		}
+		t.SubType = data[0][0]
+		if t.SubType == 'f' {
+			a := make([]float64, len(data)-1)
+			for i, d := range data[1:] {
+				a[i], err = strconv.ParseFloat(d, 64)
+				if err != nil {
+					break
+				}
+			}
+			t.Value = a
+		} else {
+			a := make([]int, len(data)-1)
+			for i, d := range data[1:] {
+				a[i], err = strconv.Atoi(d)
+				if err != nil {
+					break
+				}
+			}
+			t.Value = a
+		}
+	default:
+		return t, errors.New("Unknown optional field type (" + s + ")")
+	}
+	if err != nil {
+		return t, errors.New("Invalid optional field value (" + s + ")")
+	}
+	return t, nil
+}
+
+// Parse an alignment line
+func parseRecord(line string) (Record, error) {
+	var a Record
+	data := strings.Split(line, "\t")
+	if len(data) < nFields {
+		return a, errors.New("Alignment line with less than 11 fields")
+	}
+
+	a.QName = data[0]
+	flag, err := strconv.ParseUint(data[1], 10, 16)
+	if err != nil {
+		return a, errors.New("Invalid FLAG (" + data[1] + ")")
+	}
+	a.Flag = uint16(flag)
+	a.RName = data[2]
+	a.Pos, err = strconv.Atoi(data[3])
+	if err != nil {
+		return a, errors.New("Invalid POS (" + data[3] + ")")
+	}
+	a.MapQ, err = strconv.Atoi(data[4])
+	if err != nil {
+		return a, errors.New("Invalid MAPQ (" + data[4] + ")")
+	}
+	a.Cigar, err = parseCigar(data[5])
+	if err != nil {
+		return a, err
+	}
+	a.RNext = data[6]
+	a.PNext, err = strconv.Atoi(data[7])
+	if err != nil {
+		return a, errors.New("Invalid PNEXT (" + data[7] + ")")
+	}
+	a.TLen, err = strconv.Atoi(data[8])
+	if err != nil {
+		return a, errors.New("Invalid TLEN (" + data[8] + ")")
+	}
+	if data[9] != "*" {
+		a.Seq = []byte(data[9])
+	}
+	if data[10] != "*" {
+		a.Qual = []byte(data[10])
+		if a.Seq != nil && len(a.Qual) != len(a.Seq) {
+			return a, errors.New("SEQ and QUAL with different lengths (" + a.QName + ")")
+		}
+	}
+	for _, d := range data[nFields:] {
+		t, err := parseTag(d)
+		if err != nil {
+			return a, err
+		}
+		a.Tags = append(a.Tags, t)
+	}
+	return a, nil
+}
+
+// Read the next alignment record
+// NOTE: at the end of the file, an empty record is returned with no error
+func (r *Reader) ReadRecord() (Record, error) {
+	var a Record
+	if !r.started {
+		err := r.readHeader()
+		if err != nil {
+			return a, err
+		}
+	}
+
+	// Next alignment line
+	line := r.pending
+	r.pending = ""
+	for line == "" && r.scan.Scan() {
+		line = string(r.scan.Bytes())
+		r.line++
+	}
+	if line == "" {
+		r.eof = true
+		return a, r.scan.Err()
+	}
+	if line[0] == HeaderPreffix {
+		return a, r.parseError(errors.New("Header line after alignment lines"))
+	}
+
+	a, err := parseRecord(line)
+	if err != nil {
+		return a, r.parseError(err)
+	}
+	r.count++
+	return a, nil
+}
+
+// Read the next read as a sequence
+// NOTE: secondary and supplementary alignments are skipped
+func (r *Reader) Read() (seq.Seq, error) {
+	for {
+		a, err := r.ReadRecord()
+		if err != nil || r.eof {
+			return seq.Seq{}, err
+		}
+		if a.HasFlag(FlagSecondary | FlagSupplementary) {
+			continue
+		}
+		if len(a.Seq) == 0 {
+			return seq.Seq{}, seqitf.NewParseError("SAM", r.line, r.count, errors.New("Primary alignment without sequence ("+a.QName+")"))
+		}
+		return a.ToSeq(), nil
+	}
+}
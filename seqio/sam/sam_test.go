@@ -0,0 +1,167 @@
+package sam
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hdevillers/go-seq/seqio/scanner"
+)
+
+const testSam = "@HD\tVN:1.6\tSO:coordinate\n" +
+	"@SQ\tSN:chr1\tLN:1000\n" +
+	"@RG\tID:rg1\tSM:sample\n" +
+	"@CO\tfree text\n" +
+	"r1\t0\tchr1\t10\t60\t2S4M1I3M\t=\t100\t95\tACGTACGTAC\tIIIIIIIIII\tNM:i:1\tRG:Z:rg1\n" +
+	"r1\t256\tchr1\t500\t0\t10M\t*\t0\t0\t*\t*\n" +
+	"r1\t2048\tchr1\t700\t0\t5H5M\t*\t0\t0\tACGTA\t*\n" +
+	"\t4\t*\t0\t0\t*\t*\t0\t0\tGGA\t!!!\n" +
+	"r2\t16\tchr1\t20\t60\t4M\t*\t0\t0\tAACG\tABCD\n"
+
+func TestParseCigar(t *testing.T) {
+	tests := []struct {
+		s     string
+		cigar Cigar
+		ref   int
+	}{
+		{"*", nil, 0},
+		{"10M", Cigar{{'M', 10}}, 10},
+		{"2S4M1I3M2D5N1=1X3H1P", Cigar{{'S', 2}, {'M', 4}, {'I', 1}, {'M', 3}, {'D', 2}, {'N', 5}, {'=', 1}, {'X', 1}, {'H', 3}, {'P', 1}}, 16},
+		{"120M", Cigar{{'M', 120}}, 120},
+	}
+	for _, tt := range tests {
+		c, err := parseCigar(tt.s)
+		if err != nil || !reflect.DeepEqual(c, tt.cigar) {
+			t.Errorf("parseCigar(%q) = %v, %v", tt.s, c, err)
+			continue
+		}
+		if c.String() != tt.s || c.RefLength() != tt.ref {
+			t.Errorf("%q: String = %q, RefLength = %d", tt.s, c.String(), c.RefLength())
+		}
+	}
+	for _, s := range []string{"M", "10", "10Q", "4M3", "-1M"} {
+		if _, err := parseCigar(s); err == nil {
+			t.Errorf("parseCigar(%q): no error", s)
+		}
+	}
+}
+
+func TestParseTag(t *testing.T) {
+	tests := []struct {
+		s   string
+		tag Tag
+	}{
+		{"NM:i:-3", Tag{"NM", 'i', 0, -3}},
+		{"RG:Z:rg 1", Tag{"RG", 'Z', 0, "rg 1"}},
+		{"XA:A:c", Tag{"XA", 'A', 0, "c"}},
+		{"XF:f:1.5e-2", Tag{"XF", 'f', 0, 0.015}},
+		{"XH:H:1AE3", Tag{"XH", 'H', 0, "1AE3"}},
+		{"XB:B:c,-1,2,3", Tag{"XB", 'B', 'c', []int{-1, 2, 3}}},
+		{"XB:B:f,0.5,2", Tag{"XB", 'B', 'f', []float64{0.5, 2}}},
+		{"XE:Z:", Tag{"XE", 'Z', 0, ""}},
+	}
+	for _, tt := range tests {
+		tag, err := parseTag(tt.s)
+		if err != nil || !reflect.DeepEqual(tag, tt.tag) {
+			t.Errorf("parseTag(%q) = %+v, %v, want %+v", tt.s, tag, err, tt.tag)
+		}
+	}
+	for _, s := range []string{"NM:i", "NM-i:1", "NM:i:x", "XF:f:x", "XQ:Q:1", "XB:B:cc,1", "XB:B:i,1,x"} {
+		if _, err := parseTag(s); err == nil {
+			t.Errorf("parseTag(%q): no error", s)
+		}
+	}
+}
+
+func TestParseRecord(t *testing.T) {
+	a, err := parseRecord("r1\t99\tchr1\t10\t60\t4M\t=\t100\t95\tACGT\tABCD\tNM:i:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.QName != "r1" || a.Flag != 99 || a.RName != "chr1" || a.Pos != 10 || a.MapQ != 60 ||
+		a.Cigar.String() != "4M" || a.RNext != "=" || a.PNext != 100 || a.TLen != 95 ||
+		string(a.Seq) != "ACGT" || string(a.Qual) != "ABCD" || len(a.Tags) != 1 {
+		t.Errorf("parseRecord = %+v", a)
+	}
+	if !a.HasFlag(FlagPaired) || !a.HasFlag(FlagRead1|FlagRead2) || a.HasFlag(FlagReverse) {
+		t.Errorf("Flag = %d", a.Flag)
+	}
+	if tag, ok := a.Tag("NM"); !ok || tag.Value != 0 {
+		t.Errorf("Tag(NM) = %v, %v", tag, ok)
+	}
+
+	// Unavailable sequence and quality
+	a, err = parseRecord("r1\t4\t*\t0\t0\t*\t*\t0\t0\t*\t*")
+	if err != nil || a.Seq != nil || a.Qual != nil || a.Cigar != nil {
+		t.Errorf("parseRecord = %+v, %v", a, err)
+	}
+
+	bad := []string{
+		"r1\t0\tchr1\t10\t60\t4M\t=\t100\t95\tACGT",
+		"r1\tx\tchr1\t10\t60\t4M\t=\t100\t95\tACGT\tABCD",
+		"r1\t0\tchr1\tx\t60\t4M\t=\t100\t95\tACGT\tABCD",
+		"r1\t0\tchr1\t10\tx\t4M\t=\t100\t95\tACGT\tABCD",
+		"r1\t0\tchr1\t10\t60\t4Q\t=\t100\t95\tACGT\tABCD",
+		"r1\t0\tchr1\t10\t60\t4M\t=\tx\t95\tACGT\tABCD",
+		"r1\t0\tchr1\t10\t60\t4M\t=\t100\tx\tACGT\tABCD",
+		"r1\t0\tchr1\t10\t60\t4M\t=\t100\t95\tACGT\tABC",
+		"r1\t0\tchr1\t10\t60\t4M\t=\t100\t95\tACGT\tABCD\tNM",
+	}
+	for _, l := range bad {
+		if _, err := parseRecord(l); err == nil {
+			t.Errorf("parseRecord(%q): no error", l)
+		}
+	}
+}
+
+func TestRead(t *testing.T) {
+	r := NewReader(scanner.NewScanner(strings.NewReader(testSam)))
+	h, err := r.Header()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.Version != "1.6" || h.SortOrder != "coordinate" || len(h.References) != 1 || len(h.ReadGroups) != 1 || len(h.Comments) != 1 {
+		t.Errorf("Header = %+v", h)
+	}
+
+	// Secondary and supplementary alignments are skipped, a nameless record
+	// does not end the file and reverse reads are reverse-complemented
+	want := []struct{ id, seq, qual string }{
+		{"r1", "ACGTACGTAC", "IIIIIIIIII"},
+		{"", "GGA", "!!!"},
+		{"r2", "CGTT", "DCBA"},
+	}
+	for _, w := range want {
+		s, err := r.Read()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s.Id != w.id || string(s.Sequence) != w.seq || string(s.Quality.StrScore) != w.qual {
+			t.Errorf("Read = %q %s %s, want %q %s %s", s.Id, s.Sequence, s.Quality.StrScore, w.id, w.seq, w.qual)
+		}
+	}
+	s, err := r.Read()
+	if err != nil || s.Length() != 0 || !r.IsEOF() {
+		t.Errorf("end of file: %v, %d, %v", err, s.Length(), r.IsEOF())
+	}
+
+	// Primary alignment without sequence
+	r = NewReader(scanner.NewScanner(strings.NewReader("r1\t0\tchr1\t10\t60\t4M\t*\t0\t0\t*\t*\n")))
+	if _, err = r.Read(); err == nil {
+		t.Errorf("no error with a primary alignment without sequence")
+	}
+}
+
+func TestToSeq(t *testing.T) {
+	a := Record{QName: "r", Flag: FlagReverse, Seq: []byte("AACGTN"), Qual: []byte("ABCDEF")}
+	s := a.ToSeq()
+	if s.Id != "r" || string(s.Sequence) != "NACGTT" || string(s.Quality.StrScore) != "FEDCBA" {
+		t.Errorf("ToSeq = %s %s %s", s.Id, s.Sequence, s.Quality.StrScore)
+	}
+	if string(a.Seq) != "AACGTN" {
+		t.Errorf("the record was modified: %s", a.Seq)
+	}
+	if s.Quality.IntScore[0] != 'F'-33 {
+		t.Errorf("IntScore = %v", s.Quality.IntScore)
+	}
+}
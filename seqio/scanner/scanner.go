@@ -0,0 +1,90 @@
+package scanner
+
+import (
+	"bufio"
+	"io"
+)
+
+/*
+	Line scanner built on a bufio.Reader. Unlike bufio.Scanner, there is
+	no limit on the line length (e.g. long reads or unwrapped
+	chromosomes): lines larger than the buffer are accumulated.
+	It implements seqitf.FileScanner.
+*/
+
+const (
+	DefaultBufferSize int = 1 << 16
+)
+
+// Line scanner struct
+type Scanner struct {
+	reader *bufio.Reader
+	line   []byte
+	long   []byte
+	err    error
+	done   bool
+}
+
+// Generate a new scanner
+func NewScanner(r io.Reader) *Scanner {
+	return NewScannerSize(r, DefaultBufferSize)
+}
+
+// Generate a new scanner with a given buffer size
+func NewScannerSize(r io.Reader, size int) *Scanner {
+	return &Scanner{
+		reader: bufio.NewReaderSize(r, size),
+	}
+}
+
+// Read the next line, return false at the end of the input or on error
+// NOTE: the end-of-line characters ("\n" or "\r\n") are removed
+func (s *Scanner) Scan() bool {
+	if s.done {
+		return false
+	}
+
+	line, err := s.reader.ReadSlice('\n')
+	if err == bufio.ErrBufferFull {
+		// Long line: accumulate the chunks
+		s.long = append(s.long[:0], line...)
+		for err == bufio.ErrBufferFull {
+			line, err = s.reader.ReadSlice('\n')
+			s.long = append(s.long, line...)
+		}
+		line = s.long
+	}
+
+	if err != nil {
+		s.done = true
+		if err != io.EOF {
+			s.err = err
+			return false
+		}
+		if len(line) == 0 {
+			return false
+		}
+	}
+
+	// Remove the end-of-line characters (also a final "\r" without "\n")
+	n := len(line)
+	if n > 0 && line[n-1] == '\n' {
+		n--
+	}
+	if n > 0 && line[n-1] == '\r' {
+		n--
+	}
+	s.line = line[:n]
+	return true
+}
+
+// Get the last scanned line
+// NOTE: the returned slice is only valid until the next call to Scan
+func (s *Scanner) Bytes() []byte {
+	return s.line
+}
+
+// Get the scanning error (io.EOF is not an error)
+func (s *Scanner) Err() error {
+	return s.err
+}
@@ -0,0 +1,86 @@
+package scanner
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+const testBufferSize = 16
+
+// Scan all the lines of a text
+func scanLines(t *testing.T, text string, size int) []string {
+	t.Helper()
+	s := NewScannerSize(strings.NewReader(text), size)
+	var lines []string
+	for s.Scan() {
+		lines = append(lines, string(s.Bytes()))
+	}
+	if err := s.Err(); err != nil {
+		t.Fatalf("Err = %v", err)
+	}
+	if s.Scan() {
+		t.Errorf("Scan returned true after the end")
+	}
+	return lines
+}
+
+func TestScanLines(t *testing.T) {
+	long := strings.Repeat("ACGT", 1<<20)
+	buf := strings.Repeat("A", testBufferSize)
+	tests := []struct {
+		name  string
+		text  string
+		lines []string
+	}{
+		{"empty", "", nil},
+		{"empty line", "\n", []string{""}},
+		{"no final end-of-line", "a\nb", []string{"a", "b"}},
+		{"crlf", "a\r\nb\r\n\r\nc", []string{"a", "b", "", "c"}},
+		{"single cr kept", "a\rb\n", []string{"a\rb"}},
+		{"crlf without final end-of-line", "a\r\nb\r", []string{"a", "b"}},
+		{"final cr only", "\r", []string{""}},
+		{"long line with final cr", buf + buf + "\r", []string{buf + buf}},
+		{"buffer size - 1", buf[1:] + "\n" + "x\n", []string{buf[1:], "x"}},
+		{"buffer size", buf + "\n" + "x\n", []string{buf, "x"}},
+		{"buffer size + 1", buf + "C\n" + "x\n", []string{buf + "C", "x"}},
+		{"buffer size with crlf", buf[2:] + "\r\n" + "x", []string{buf[2:], "x"}},
+		{"split crlf", buf[1:] + "\r\n" + "x", []string{buf[1:], "x"}},
+		{"long line without end-of-line", buf + buf + "C", []string{buf + buf + "C"}},
+		{"multi-MB line", ">s\n" + long + "\n>t\n", []string{">s", long, ">t"}},
+	}
+	for _, tt := range tests {
+		lines := scanLines(t, tt.text, testBufferSize)
+		if !reflect.DeepEqual(lines, tt.lines) {
+			if len(lines) != len(tt.lines) {
+				t.Errorf("%s: %d lines, want %d", tt.name, len(lines), len(tt.lines))
+			} else {
+				t.Errorf("%s: %q, want %q", tt.name, lines, tt.lines)
+			}
+		}
+	}
+
+	// Default buffer size
+	lines := scanLines(t, ">s\n"+long+"\r\n", DefaultBufferSize)
+	if len(lines) != 2 || lines[1] != long {
+		t.Errorf("multi-MB line with the default buffer size")
+	}
+}
+
+func TestScanError(t *testing.T) {
+	errRead := errors.New("read error")
+	r := iotest.TimeoutReader(strings.NewReader(strings.Repeat("A", 100) + "\n"))
+	s := NewScannerSize(r, testBufferSize)
+	for s.Scan() {
+	}
+	if s.Err() == nil {
+		t.Errorf("no error with a failing reader")
+	}
+
+	s = NewScanner(iotest.ErrReader(errRead))
+	if s.Scan() || !errors.Is(s.Err(), errRead) {
+		t.Errorf("Err = %v, want %v", s.Err(), errRead)
+	}
+}
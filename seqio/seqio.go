@@ -1,16 +1,12 @@
 package seqio
 
 import (
-	"bufio"
 	"errors"
+	"fmt"
+	"io"
 	"os"
 
-	gzip "github.com/klauspost/pgzip"
-
 	"github.com/hdevillers/go-seq/seq"
-	"github.com/hdevillers/go-seq/seqio/fasta"
-	"github.com/hdevillers/go-seq/seqio/fastnq"
-	"github.com/hdevillers/go-seq/seqio/fastq"
 	"github.com/hdevillers/go-seq/seqio/seqitf"
 )
 
@@ -18,12 +14,60 @@ const (
 	defaultCompress = false
 )
 
+// Compression types
+type Compression int
+
+const (
+	NoCompression Compression = iota
+	Gzip
+	Bgzf
+	Bzip2
+	Xz
+	Zstd
+	AutoCompression
+)
+
+// Compression names
+func (c Compression) String() string {
+	switch c {
+	case NoCompression:
+		return "none"
+	case Gzip:
+		return "gzip"
+	case Bgzf:
+		return "bgzf"
+	case Bzip2:
+		return "bzip2"
+	case Xz:
+		return "xz"
+	case Zstd:
+		return "zstd"
+	case AutoCompression:
+		return "auto"
+	}
+	return "unknown"
+}
+
+// Errors (see seqitf)
+var (
+	ErrUnsupportedFormat = seqitf.ErrUnsupportedFormat
+	ErrEmptySequence     = seqitf.ErrEmptySequence
+)
+
+// Parsing error with its location (line and record numbers)
+type ParseError = seqitf.ParseError
+
 // Reader structure
 type Reader struct {
 	fcloser seqitf.FileCloser
 	sreader seqitf.SeqReader
+	reuser  seqitf.SeqReuser
+	format  string
+	source  string
 	seq     seq.Seq
 	err     error
+	errStop bool
+	multi   *multiFiles
 }
 
 // Writer structure
@@ -35,94 +79,173 @@ type Writer struct {
 
 // Create a new reader (from a file name and a format)
 func NewReader(file string, format string, compress ...bool) *Reader {
-	// Open file in read mode
-	var f *os.File
-	var err error
-	if file == "STDIN" {
-		f = os.Stdin
-	} else {
-		f, err = os.Open(file)
-		if err != nil {
-			return &Reader{
-				err: err,
-			}
-		}
-	}
-
 	// Check compression argument
 	if len(compress) == 0 {
 		compress = append(compress, defaultCompress)
 	}
-
-	// Inti. the bufio.Scanner
-	var fs seqitf.FileScanner
-	var fc seqitf.FileCloser
-
 	if compress[0] {
-		// Need de-compression
-		fgzip, err := gzip.NewReader(f)
-		if err != nil {
-			return &Reader{
-				err: err,
-			}
-		}
-		fc = fgzip
-		fs = bufio.NewScanner(fgzip)
-	} else {
-		// No de-compression needed
-		fs = bufio.NewScanner(f)
-		fc = f
+		return NewCompressedReader(file, format, Gzip)
 	}
+	return NewCompressedReader(file, format, NoCompression)
+}
 
-	var sreader seqitf.SeqReader
-	switch format {
-	case "fasta", "fa":
-		sreader = fasta.NewReader(fs)
+// Create a new reader (from a file name, a format and a compression type)
+// NOTE: with the "auto" format, the compression and the format are both
+// detected from the first bytes of the input
+func NewCompressedReader(file string, format string, c Compression) *Reader {
+	f, err := openFile(file)
+	if err != nil {
 		return &Reader{
-			fcloser: fc,
-			sreader: sreader}
-	case "fastq", "fq":
-		sreader = fastq.NewReader(fs)
-		return &Reader{
-			fcloser: fc,
-			sreader: sreader,
+			err: err,
 		}
-	case "fastnq", "fnq":
-		sreader = fastnq.NewReader(fs)
+	}
+	r := newReader(f, f, format, newOptions(c, nil))
+	r.source = file
+	return r
+}
+
+// Open file in read mode (STDIN for the standard input)
+func openFile(file string) (*os.File, error) {
+	if file == "STDIN" {
+		return os.Stdin, nil
+	}
+	return os.Open(file)
+}
+
+// Open a sequence file (the compression is detected by default)
+// NOTE: readers created this way stop on the first error, Err must be
+// checked after the Next loop
+func Open(file string, format string, opts ...Option) (*Reader, error) {
+	o := newOptions(AutoCompression, opts)
+	f, err := openFile(file)
+	if err != nil {
+		return nil, err
+	}
+	r := newReader(f, f, format, o)
+	if r.err != nil {
+		return nil, r.err
+	}
+	r.source = file
+	r.errStop = true
+	return r, nil
+}
+
+// Create a new reader from an io.Reader (with Options)
+// NOTE: the compression is detected by default, r is not closed by Close
+func NewReaderFrom(r io.Reader, format string, opts ...Option) *Reader {
+	o := newOptions(AutoCompression, opts)
+	return newReader(r, closeFunc(func() {}), format, o)
+}
+
+// Set up the decompression and the parser of an input
+// NOTE: f is closed in case of error and by Reader.Close
+func newReader(in io.Reader, f seqitf.FileCloser, format string, o Options) *Reader {
+	in, fc, sf, err := prepareInput(in, f, format, o.Compression)
+	if err != nil {
 		return &Reader{
-			fcloser: fc,
-			sreader: sreader,
+			err: err,
 		}
-	default:
+	}
+
+	sreader, err := newSeqReader(sf, in, o)
+	if err != nil {
+		fc.Close()
 		return &Reader{
-			err: errors.New("[SEQIO READER]: Unsupported format (" + format + ")."),
+			err: err,
 		}
 	}
+	r := &Reader{
+		fcloser: fc,
+		sreader: sreader,
+		format:  sf.Name,
+	}
+	if o.Reuse {
+		r.reuser, _ = sreader.(seqitf.SeqReuser)
+	}
+	return r
+}
+
+// Create the parser of a format and set its options
+func newSeqReader(sf *Format, in io.Reader, o Options) (seqitf.SeqReader, error) {
+	sreader, err := sf.NewReader(in, o)
+	if err != nil {
+		return nil, err
+	}
+	if v, ok := sreader.(seqitf.SeqValidator); ok {
+		v.SetValidator(seqitf.Validator{
+			Alphabet: o.Alphabet,
+			Replace:  o.Replace,
+		})
+	}
+	return sreader, nil
 }
 
 // Read next sequence
+// NOTE: with readers created by Open, Next returns false on error, otherwise
+// it returns true and the error must be checked (CheckPanic or Err)
 func (r *Reader) Next() bool {
-	if r.sreader.IsEOF() {
-		return false
-	} else {
-		r.seq, r.err = r.sreader.Read()
-		// NOTE: Some parsers return an empty sequence at the end with out error
-		if r.err == nil && r.seq.Length() == 0 {
+	for {
+		// Reader created with an error or stopped by an error
+		if r.sreader == nil || (r.errStop && r.err != nil) {
+			return false
+		}
+		if !r.sreader.IsEOF() {
+			if r.reuser != nil {
+				r.err = r.reuser.ReadInto(&r.seq)
+			} else {
+				r.seq, r.err = r.sreader.Read()
+			}
+			if r.err != nil {
+				return !r.errStop
+			}
+			// NOTE: Some parsers return an empty sequence at the end with out error
+			if r.seq.Length() != 0 {
+				return true
+			}
+		}
+		// Continue with the next file (multi-file readers)
+		if !r.nextFile() {
 			return false
-		} else {
-			return true
 		}
 	}
 }
 
-// Get the current sequence
+// Get the current sequence (overwritten by Next with WithReuse)
 func (r *Reader) Seq() seq.Seq {
 	return r.seq
 }
 
+// Get the warning raised while reading the current sequence (nil if none)
+// NOTE: only the fastq reader raises warnings (quality issues)
+func (r *Reader) Warning() error {
+	if w, ok := r.sreader.(seqitf.SeqWarner); ok {
+		return w.Warning()
+	}
+	return nil
+}
+
+// Get the name of the file of the current sequence (empty with an
+// io.Reader)
+func (r *Reader) Source() string {
+	return r.source
+}
+
+// Get the format of the input (useful with the "auto" format)
+func (r *Reader) Format() string {
+	return r.format
+}
+
 // Close file handle
-func (r *Reader) Close() {
-	r.fcloser.Close()
+func (r *Reader) Close() error {
+	if r.fcloser == nil {
+		return nil
+	}
+	return r.fcloser.Close()
+}
+
+// Get the last error
+func (r *Reader) Err() error {
+	return r.err
 }
 
 // Get errors
@@ -134,6 +257,51 @@ func (r *Reader) CheckPanic() {
 
 // Create a new Writer (from a file name and a format)
 func NewWriter(file string, format string, compress ...bool) *Writer {
+	// Check compression argument
+	if len(compress) == 0 {
+		compress = append(compress, defaultCompress)
+	}
+	if compress[0] {
+		return NewCompressedWriter(file, format, Gzip)
+	}
+	return NewCompressedWriter(file, format, NoCompression)
+}
+
+// Create a new Writer (from a file name, a format and a compression type)
+// NOTE: Bgzf output is compatible with samtools/htslib (bgzip), bzip2 is
+// only supported in read mode and AutoCompression selects the compression
+// from the file extension
+func NewCompressedWriter(file string, format string, c Compression) *Writer {
+	return createWriter(file, format, newOptions(c, nil))
+}
+
+// Create the output file and its writer
+func createWriter(file string, format string, o Options) *Writer {
+	// Select the format from the file extension if required
+	if format == "auto" {
+		format = FormatFromExtension(file)
+		if format == "" {
+			return &Writer{
+				err: fmt.Errorf("[SEQIO WRITER]: Unable to select the format from the file name (%s).", file),
+			}
+		}
+	}
+
+	// Check the format and the compression before creating the file
+	if sf, ok := LookupFormat(format); !ok || sf.NewWriter == nil {
+		return &Writer{
+			err: fmt.Errorf("[SEQIO WRITER]: %w (%s).", ErrUnsupportedFormat, format),
+		}
+	}
+	if o.Compression == AutoCompression {
+		o.Compression = CompressionFromExtension(file)
+	}
+	if !isWritable(o.Compression) {
+		return &Writer{
+			err: errors.New("[SEQIO WRITER]: Unsupported compression (" + o.Compression.String() + ")."),
+		}
+	}
+
 	// Open a file in write/overide mode
 	var f *os.File
 	var err error
@@ -149,57 +317,98 @@ func NewWriter(file string, format string, compress ...bool) *Writer {
 		}
 	}
 
-	// Check compression argument
-	if len(compress) == 0 {
-		compress = append(compress, defaultCompress)
+	// Remove the file if the writer cannot be set up
+	w := newWriter(f, f, format, o)
+	if w.swriter == nil && file != "" {
+		os.Remove(file)
 	}
+	return w
+}
 
-	// Inti. the bufio.Scanner
-	var fw seqitf.FileWriter
-	var fc seqitf.FileCloser
+// Create a sequence file (the compression is selected from the file
+// extension by default)
+// NOTE: with the "auto" format, the format is also selected from the file
+// extension (e.g., "reads.fq.gz")
+func Create(file string, format string, opts ...Option) (*Writer, error) {
+	o := newOptions(AutoCompression, opts)
+	w := createWriter(file, format, o)
+	if w.err != nil {
+		return nil, w.err
+	}
+	return w, nil
+}
 
-	if compress[0] {
-		// Need de-compression
-		fgz := gzip.NewWriter(f)
-		fw = fgz
-		fc = fgz
-	} else {
-		// No de-compression needed
-		fw = bufio.NewWriter(f)
-		fc = f
+// Create a new Writer to an io.Writer (with Options)
+// NOTE: the output is not compressed by default, w is not closed by Close
+func NewWriterTo(w io.Writer, format string, opts ...Option) *Writer {
+	o := newOptions(NoCompression, opts)
+	if o.Compression == AutoCompression {
+		return &Writer{
+			err: errors.New("[SEQIO WRITER]: The compression must be explicit with an io.Writer."),
+		}
 	}
+	return newWriter(w, closeFunc(func() {}), format, o)
+}
 
-	var swriter seqitf.SeqWriter
-	switch format {
-	case "fasta", "fa":
-		swriter = fasta.NewWriter(fw)
+// Set up the compressor and the formatter of an output
+// NOTE: f is closed in case of error and by Writer.Close
+func newWriter(out io.Writer, f seqitf.FileCloser, format string, o Options) *Writer {
+	// Inti. the compressor
+	fw, fc, err := newCompressor(o.Compression, out, f)
+	if err != nil {
+		f.Close()
 		return &Writer{
-			fcloser: fc,
-			swriter: swriter,
+			err: err,
 		}
-	case "fastq", "fq", "fastnq", "fnq":
-		swriter = fastq.NewWriter(fw)
+	}
+
+	sf, ok := LookupFormat(format)
+	if !ok || sf.NewWriter == nil {
+		fc.Close()
 		return &Writer{
-			fcloser: fc,
-			swriter: swriter,
+			err: fmt.Errorf("[SEQIO WRITER]: %w (%s).", ErrUnsupportedFormat, format),
 		}
-	default:
+	}
+	swriter, err := sf.NewWriter(fw, o)
+	if err != nil {
+		fc.Close()
 		return &Writer{
-			err: errors.New("[SEQIO WRITER]: Unsupported format (" + format + ")."),
+			err: err,
 		}
 	}
+	return &Writer{
+		fcloser: fc,
+		swriter: swriter,
+	}
 }
 
 // Append a sequence in the output file
-func (w *Writer) Write(s seq.Seq) {
+func (w *Writer) Write(s seq.Seq) error {
+	// Writer created with an error
+	if w.swriter == nil {
+		return w.err
+	}
 	w.err = w.swriter.Write(s)
+	return w.err
 }
 
 // Close output file
-func (w *Writer) Close() {
+func (w *Writer) Close() error {
+	if w.swriter == nil {
+		return w.err
+	}
 	err := w.swriter.Flush()
+	cerr := w.fcloser.Close()
+	if err == nil {
+		err = cerr
+	}
 	w.err = err
-	w.fcloser.Close()
+	return err
+}
+
+// Get the last error
+func (w *Writer) Err() error {
+	return w.err
 }
 
 // Throw a panic in case of error
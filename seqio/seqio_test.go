@@ -0,0 +1,456 @@
+package seqio
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hdevillers/go-seq/alphabet"
+	"github.com/hdevillers/go-seq/seq"
+	"github.com/hdevillers/go-seq/seqio/bam"
+	"github.com/hdevillers/go-seq/seqio/bgzf"
+	"github.com/hdevillers/go-seq/seqio/seqitf"
+)
+
+func TestCreateUnsupported(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		file, format string
+		opts         []Option
+	}{
+		{"out.fa.bz2", "auto", nil},
+		{"out.fa", "fasta", []Option{WithCompression(Bzip2)}},
+		{"out.bam", "auto", nil},
+		{"out.txt", "auto", nil},
+		{"out.fa", "unknown", nil},
+	}
+	for _, tt := range tests {
+		file := filepath.Join(dir, tt.file)
+		if _, err := Create(file, tt.format, tt.opts...); err == nil {
+			t.Errorf("Create(%s, %s): no error", tt.file, tt.format)
+		}
+		if _, err := os.Stat(file); !os.IsNotExist(err) {
+			t.Errorf("Create(%s, %s): the file was created", tt.file, tt.format)
+		}
+	}
+}
+
+func TestReadCRLF(t *testing.T) {
+	// CRLF line endings without final end-of-line (default alphabet check)
+	inputs := map[string]string{
+		"fasta":  ">a\r\nACGT\r\nAC\r",
+		"fastq":  "@a\r\nACGTAC\r\n+\r\nIIIIII\r",
+		"fastnq": "@a\r\nACGTAC\r\n+\r\nIIIIII\r",
+	}
+	for format, text := range inputs {
+		r := NewReaderFrom(strings.NewReader(text), format)
+		if !r.Next() || r.Err() != nil {
+			t.Fatalf("%s: no sequence (%v)", format, r.Err())
+		}
+		if s := r.Seq(); s.Id != "a" || string(s.Sequence) != "ACGTAC" {
+			t.Errorf("%s: %q %q", format, s.Id, s.Sequence)
+		}
+	}
+}
+
+func TestReuse(t *testing.T) {
+	inputs := map[string]string{
+		"fasta":  ">a first\nACGT\nAC\n>bb\nT\n>c third\nGGGGGGGGGG\n",
+		"fastq":  "@a first\nACGTAC\n+\nIIIIII\n@bb\nT\n+\nI\n@c third\nGGGGGGGGGG\n+\nIIIIIIIIII\n",
+		"fastnq": "@a first\nACGTAC\n+\nIIIIII\n@bb\nT\n+\nI\n@c third\nGGGGGGGGGG\n+\nIIIIIIIIII\n",
+	}
+	want := []string{"a first ACGTAC", "bb  T", "c third GGGGGGGGGG"}
+	for format, text := range inputs {
+		r := NewReaderFrom(strings.NewReader(text), format, WithReuse())
+		if r.reuser == nil {
+			t.Errorf("%s: no ReadInto", format)
+		}
+		var got []string
+		for r.Next() {
+			s := r.Seq()
+			// Copies of the shared strings and sequence
+			got = append(got, string([]byte(s.Id))+" "+string([]byte(s.Desc))+" "+string(s.Sequence))
+		}
+		if r.Err() != nil || strings.Join(got, ",") != strings.Join(want, ",") {
+			t.Errorf("%s: %q, %v", format, got, r.Err())
+		}
+	}
+}
+
+func TestAlphabet(t *testing.T) {
+	// The reported position counts the previous sequence lines
+	text := ">a\nACGT\nACxT\n"
+	r := NewReaderFrom(strings.NewReader(text), "fasta", WithAlphabet(alphabet.DNA))
+	var e *alphabet.InvalidError
+	r.Next()
+	if !errors.As(r.Err(), &e) || e.Char != 'x' || e.Pos != 7 || e.Alphabet != alphabet.DNA {
+		t.Errorf("error %v, want 'x' at position 7", r.Err())
+	}
+
+	r = NewReaderFrom(strings.NewReader(text), "fasta", WithAlphabet(alphabet.DNA), WithReplace('N'))
+	if !r.Next() || string(r.Seq().Sequence) != "ACGTACNT" || r.Seq().Alphabet != alphabet.DNA {
+		t.Errorf("replaced sequence %s, %v", r.Seq().Sequence, r.Err())
+	}
+}
+
+func TestBatchWarnings(t *testing.T) {
+	var b strings.Builder
+	for i := 0; i < 200; i++ {
+		qual := "IIII"
+		if i == 150 {
+			qual = "I\x1fhI"
+		}
+		b.WriteString("@r\nACGT\n+\n" + qual + "\n")
+	}
+	r, err := NewBatchReader(strings.NewReader(b.String()), "fastq", WithThreads(2), WithBatchSize(1000))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	var warns []error
+	for r.Next() {
+		warns = append(warns, r.Warnings()...)
+	}
+	if r.Err() != nil {
+		t.Fatal(r.Err())
+	}
+	var pe *ParseError
+	if len(warns) != 1 || !errors.As(warns[0], &pe) || pe.Record != 151 || pe.Line != 604 {
+		t.Errorf("warnings = %v, want one at record 151, line 604", warns)
+	}
+}
+
+func TestIsMate(t *testing.T) {
+	tests := []struct {
+		id1, id2 string
+		mate     bool
+	}{
+		{"r1", "r1", true},
+		{"r1/1", "r1/2", true},
+		{"r1 1:N:0:ACGT", "r1 2:N:0:ACGT", true},
+		{"r1/1 1:N:0:ACGT", "r1/2 2:Y:0:ACGT", true},
+		{"r1\tdesc", "r1 other", true},
+		{"r1/1", "r1/1", false},
+		{"r1/2", "r1/1", false},
+		{"r1/1", "r1", false},
+		{"r1 1:N:0:ACGT", "r1 1:N:0:ACGT", false},
+		{"r1 2:N:0:ACGT", "r1 1:N:0:ACGT", false},
+		{"r1/1", "r2/2", false},
+		{"r1 1:N:0:ACGT", "r2 2:N:0:ACGT", false},
+	}
+	for _, tt := range tests {
+		if IsMate(tt.id1, tt.id2) != tt.mate {
+			t.Errorf("IsMate(%q, %q) = %v", tt.id1, tt.id2, !tt.mate)
+		}
+	}
+}
+
+func TestOpenPaired(t *testing.T) {
+	dir := t.TempDir()
+	r1 := filepath.Join(dir, "r1.fq")
+	r2 := filepath.Join(dir, "r2.fq")
+	os.WriteFile(r1, []byte("@x 1:N:0:A\nACGT\n+\nIIII\n@y 1:N:0:A\nACGT\n+\nIIII\n"), 0644)
+	os.WriteFile(r2, []byte("@x 2:N:0:A\nTTTT\n+\nIIII\n@y 2:N:0:A\nTTTT\n+\nIIII\n"), 0644)
+
+	p, err := OpenPaired(r1, r2, "fastq")
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := 0
+	for p.Next() {
+		n++
+	}
+	p.Close()
+	if n != 2 || p.Err() != nil {
+		t.Errorf("%d pairs, %v", n, p.Err())
+	}
+
+	// The same R1 file twice
+	p, err = OpenPaired(r1, r1, "fastq")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+	if p.Next() || !errors.Is(p.Err(), ErrMateMismatch) {
+		t.Errorf("same R1 file: %v, want %v", p.Err(), ErrMateMismatch)
+	}
+}
+
+func TestOpenMulti(t *testing.T) {
+	dir := t.TempDir()
+	var gz bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	zw.Write([]byte("@c\nACGT\n+\nIIII\n@d\nTT\n+\nII\n"))
+	zw.Close()
+	files := map[string][]byte{
+		"a.fa":    []byte(">a\nACGT\n>b\nGG\n"),
+		"b.fq.gz": gz.Bytes(),
+		"c.fa":    []byte(">e\nCCC\n"),
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := []string{"a a.fa fasta", "b a.fa fasta", "c b.fq.gz fastq", "d b.fq.gz fastq", "e c.fa fasta"}
+
+	r, err := OpenGlob(filepath.Join(dir, "*"), "auto")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for r.Next() {
+		got = append(got, r.Seq().Id+" "+filepath.Base(r.Source())+" "+r.Format())
+	}
+	r.Close()
+	if r.Err() != nil || strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("OpenGlob: %q, %v", got, r.Err())
+	}
+
+	// Given order and a missing file
+	r, err = OpenMulti([]string{filepath.Join(dir, "c.fa"), filepath.Join(dir, "a.fa"), filepath.Join(dir, "none.fa")}, "fasta")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got = nil
+	for r.Next() {
+		got = append(got, r.Seq().Id)
+	}
+	r.Close()
+	if strings.Join(got, ",") != "e,a,b" || !os.IsNotExist(r.Err()) {
+		t.Errorf("OpenMulti: %q, %v", got, r.Err())
+	}
+
+	if _, err = OpenGlob(filepath.Join(dir, "*.gb"), "auto"); err == nil {
+		t.Errorf("OpenGlob: no error without matching file")
+	}
+	if _, err = OpenMulti(nil, "auto"); err == nil {
+		t.Errorf("OpenMulti: no error without file")
+	}
+}
+
+// Test format: one sequence per line (the first line is "#LINES")
+type lineReader struct {
+	scan *bufio.Scanner
+	eof  bool
+}
+
+func (r *lineReader) Read() (seq.Seq, error) {
+	for r.scan.Scan() {
+		if line := r.scan.Text(); line != "#LINES" {
+			return seq.Seq{Id: line, Sequence: []byte(line)}, nil
+		}
+	}
+	r.eof = true
+	return seq.Seq{}, r.scan.Err()
+}
+
+func (r *lineReader) IsEOF() bool {
+	return r.eof
+}
+
+func TestRegister(t *testing.T) {
+	Register(Format{
+		Name:       "TestLines",
+		Aliases:    []string{"tl"},
+		Extensions: []string{".tl"},
+		Sniff:      prefix("#LINES\n"),
+		NewReader: func(in io.Reader, o Options) (seqitf.SeqReader, error) {
+			return &lineReader{scan: bufio.NewScanner(in)}, nil
+		},
+	})
+	for _, name := range []string{"testlines", "TL", "fa", "FASTQ", "uniprot"} {
+		if f, ok := LookupFormat(name); !ok || f == nil {
+			t.Errorf("LookupFormat(%s): not found", name)
+		}
+	}
+	if f, _ := LookupFormat("tl"); f.Name != "TestLines" {
+		t.Errorf("LookupFormat(tl) = %s", f.Name)
+	}
+	if _, ok := LookupFormat("auto"); ok {
+		t.Errorf("LookupFormat(auto): found")
+	}
+	if names := Formats(); names[len(names)-1] != "TestLines" {
+		t.Errorf("Formats = %v", names)
+	}
+	if f := FormatFromExtension("reads.TL.gz"); f != "TestLines" {
+		t.Errorf("FormatFromExtension = %s", f)
+	}
+
+	// Detected by content
+	r := NewReaderFrom(strings.NewReader("#LINES\nACGT\nTT\n"), "auto")
+	var ids []string
+	for r.Next() {
+		ids = append(ids, r.Seq().Id)
+	}
+	if r.Err() != nil || r.Format() != "TestLines" || strings.Join(ids, ",") != "ACGT,TT" {
+		t.Errorf("format %s, sequences %v, %v", r.Format(), ids, r.Err())
+	}
+
+	// Not writable
+	if _, err := Create(filepath.Join(t.TempDir(), "out.tl"), "auto"); err == nil {
+		t.Errorf("Create: no error without writer")
+	}
+
+	// Names already used (nothing is registered)
+	for _, f := range []Format{
+		{},
+		{Name: "fasta"},
+		{Name: "FQ"},
+		{Name: "auto"},
+		{Name: "testlines2", Aliases: []string{"testlines"}},
+		{Name: "testlines3", Aliases: []string{"tl3", "tl3"}},
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Register(%+v): no panic", f)
+				}
+			}()
+			Register(f)
+		}()
+	}
+	for _, name := range []string{"testlines2", "testlines3", "tl3"} {
+		if _, ok := LookupFormat(name); ok {
+			t.Errorf("LookupFormat(%s): found after a failed registration", name)
+		}
+	}
+}
+
+// Closer recording its call
+type testCloser struct {
+	closed bool
+}
+
+func (c *testCloser) Close() error {
+	c.closed = true
+	return nil
+}
+
+// Compress a text with gzip or BGZF
+func compressText(t *testing.T, text string, c Compression) []byte {
+	var b bytes.Buffer
+	var w io.WriteCloser
+	if c == Bgzf {
+		w = bgzf.NewWriter(&b)
+	} else {
+		w = gzip.NewWriter(&b)
+	}
+	if _, err := w.Write([]byte(text)); err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+	return b.Bytes()
+}
+
+func TestPrepareInput(t *testing.T) {
+	fasta := ">a desc\nACGT\n"
+	samLine := "r1\t0\tchr1\t10\t60\t4M\t*\t0\t0\tACGT\tIIII\n"
+	tests := []struct {
+		name   string
+		data   []byte
+		format string
+		start  string
+	}{
+		{"fasta", []byte(fasta), "fasta", ">"},
+		{"leading blank lines", []byte("\n \r\n" + fasta), "fasta", "\n"},
+		{"fastq", []byte("@r1\nACGT\n+\nIIII\n"), "fastq", "@r1"},
+		{"sam header", []byte("@HD\tVN:1.6\n" + samLine), "sam", "@HD"},
+		{"sam alignment", []byte(samLine), "sam", "r1"},
+		{"genbank", []byte("LOCUS       X1    4 bp    DNA\n"), "genbank", "LOCUS"},
+		{"embl", []byte("ID   X1; SV 1; linear; DNA; STD; PRO; 4 BP.\n"), "embl", "ID"},
+		{"empty", nil, "fasta", ""},
+		{"gzip fasta", compressText(t, fasta, Gzip), "fasta", ">"},
+		{"bgzf fastq", compressText(t, "@r1\nACGT\n+\nIIII\n", Bgzf), "fastq", "@r1"},
+		{"bam", compressText(t, bam.Magic+"\x00\x00\x00\x00", Bgzf), "bam", "\x1f\x8b"},
+	}
+	for _, tt := range tests {
+		c := &testCloser{}
+		in, fc, sf, err := prepareInput(bytes.NewReader(tt.data), c, "auto", AutoCompression)
+		if err != nil || sf.Name != tt.format {
+			t.Errorf("%s: %v, %v, want %s", tt.name, sf, err, tt.format)
+			continue
+		}
+
+		// The sniffed bytes are not consumed
+		head := make([]byte, len(tt.start))
+		if _, err = io.ReadFull(in, head); err != nil || string(head) != tt.start {
+			t.Errorf("%s: input starts with %q (%v), want %q", tt.name, head, err, tt.start)
+		}
+		if fc.Close(); !c.closed {
+			t.Errorf("%s: the input is not closed", tt.name)
+		}
+	}
+
+	// Explicit format of a compressed input
+	in, _, sf, err := prepareInput(bytes.NewReader(compressText(t, fasta, Gzip)), &testCloser{}, "fa", AutoCompression)
+	if data, _ := io.ReadAll(in); err != nil || sf.Name != "fasta" || string(data) != fasta {
+		t.Errorf("explicit format: %q, %v", data, err)
+	}
+
+	// Unknown formats (the input is closed)
+	for _, tt := range []struct{ data, format string }{
+		{"ACGT\n", "auto"},
+		{"#comment\n", "auto"},
+		{fasta, "unknown"},
+	} {
+		c := &testCloser{}
+		if _, _, _, err := prepareInput(strings.NewReader(tt.data), c, tt.format, AutoCompression); err == nil || !c.closed {
+			t.Errorf("%q (%s): error %v, closed %v", tt.data, tt.format, err, c.closed)
+		}
+	}
+}
+
+func TestBatchReader(t *testing.T) {
+	// FASTA records larger than the batch size
+	var b strings.Builder
+	var want []string
+	for i, n := range []int{10, 5000, 3, 20000, 7} {
+		s := strings.Repeat("ACGTTGCA"[i:i+1], n)
+		want = append(want, s)
+		b.WriteString(">s\n")
+		for j := 0; j < n; j += 60 {
+			end := j + 60
+			if end > n {
+				end = n
+			}
+			b.WriteString(s[j:end] + "\n")
+		}
+	}
+	r, err := NewBatchReader(strings.NewReader(b.String()), "fasta", WithThreads(3), WithBatchSize(100))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var seqs []string
+	for r.Next() {
+		for _, s := range r.Batch() {
+			seqs = append(seqs, string(s.Sequence))
+		}
+	}
+	r.Close()
+	if r.Err() != nil || strings.Join(seqs, ",") != strings.Join(want, ",") {
+		t.Errorf("%d sequences, %v", len(seqs), r.Err())
+	}
+
+	// Wrapped FASTQ records are rejected
+	wrapped := "@r1\nACGT\n+\nIIII\n@r2\nAC\nGT\n+\nII\nII\n@r3\nACGT\n+\nIIII\n"
+	for _, format := range []string{"fastq", "fastnq"} {
+		r, err = NewBatchReader(strings.NewReader(wrapped), format, WithThreads(2), WithBatchSize(16))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for r.Next() {
+		}
+		r.Close()
+		var pe *ParseError
+		if !errors.As(r.Err(), &pe) {
+			t.Errorf("%s: error %v with wrapped records", format, r.Err())
+		}
+	}
+}
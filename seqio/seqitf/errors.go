@@ -0,0 +1,47 @@
+package seqitf
+
+import (
+	"errors"
+	"fmt"
+)
+
+/*
+	Errors shared by seqio and the different sequence format parsers
+	(re-exported by seqio).
+*/
+
+var (
+	ErrUnsupportedFormat = errors.New("Unsupported format")
+	ErrEmptySequence     = errors.New("Empty sequence")
+)
+
+// Parsing error with its location in the input
+// NOTE: Line is 0 for binary formats, Record is the 1-based index of the
+// record being read
+type ParseError struct {
+	Format string
+	Line   int
+	Record int
+	Err    error
+}
+
+// Generate a new parsing error
+func NewParseError(format string, line, record int, err error) *ParseError {
+	return &ParseError{
+		Format: format,
+		Line:   line,
+		Record: record,
+		Err:    err,
+	}
+}
+
+func (e *ParseError) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("[%s READER]: %v (line %d, record %d).", e.Format, e.Err, e.Line, e.Record)
+	}
+	return fmt.Sprintf("[%s READER]: %v (record %d).", e.Format, e.Err, e.Record)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
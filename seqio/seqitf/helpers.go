@@ -0,0 +1,122 @@
+package seqitf
+
+import (
+	"unsafe"
+
+	"github.com/hdevillers/go-seq/alphabet"
+	"github.com/hdevillers/go-seq/seq"
+)
+
+// Buffer of the ID lines read ahead by the parsers (see SeqReuser)
+// NOTE: the line of the next record and the line of the returned record are
+// stored in two buffers swapped at each record
+type HeadBuffer struct {
+	next []byte
+	curr []byte
+	set  bool
+}
+
+// Save the ID line of the next record
+func (h *HeadBuffer) Set(line []byte) {
+	h.next = append(h.next[:0], line...)
+	h.set = len(line) > 0
+}
+
+// Return true if an ID line is waiting
+func (h *HeadBuffer) IsSet() bool {
+	return h.set
+}
+
+// Return the ID line of the next record as the current one
+// NOTE: with shared set to true, the returned string uses the buffer memory
+// and is only valid until the next call of Pop
+func (h *HeadBuffer) Pop(shared bool) string {
+	h.curr, h.next = h.next, h.curr[:0]
+	h.set = false
+	if shared {
+		return bytesToString(h.curr)
+	}
+	return string(h.curr)
+}
+
+// Split an ID line into the sequence ID and its description
+func SplitIdLine(idl string) (string, string) {
+	for i := 0; i < len(idl); i++ {
+		if idl[i] == ' ' || idl[i] == '\t' {
+			return idl[:i], idl[i+1:]
+		}
+	}
+	return idl, ""
+}
+
+// Guess if a sequence is a protein from its content (letters that are not
+// nucleotide codes)
+// NOTE: used by the writers of formats with a molecule type or unit
+func IsProtein(s []byte) bool {
+	for _, b := range s {
+		switch b {
+		case 'E', 'F', 'I', 'L', 'P', 'Q', 'e', 'f', 'i', 'l', 'p', 'q', '*':
+			return true
+		}
+	}
+	return false
+}
+
+// Convert bytes to string without copy
+func bytesToString(b []byte) string {
+	if len(b) == 0 {
+		return ""
+	}
+	return *(*string)(unsafe.Pointer(&b))
+}
+
+// Check of the sequence characters done by the readers
+// NOTE: the zero value rejects the characters out of alphabet.Any, with a
+// non zero Replace the invalid characters are replaced instead
+type Validator struct {
+	Alphabet *alphabet.Alphabet
+	Replace  byte
+}
+
+func (v *Validator) alphabet() *alphabet.Alphabet {
+	if v.Alphabet == nil {
+		return alphabet.Any
+	}
+	return v.Alphabet
+}
+
+// Check (or fix) the characters of a sequence line and append it
+// NOTE: the line may be modified
+func (v *Validator) Append(s *seq.Seq, line []byte) error {
+	a := v.alphabet()
+	if i := a.Index(line); i >= 0 {
+		if v.Replace == 0 {
+			return &alphabet.InvalidError{
+				Alphabet: a,
+				Char:     line[i],
+				Pos:      s.Length() + i + 1,
+			}
+		}
+		a.Replace(line, v.Replace)
+	}
+	s.AppendSequence(line)
+	s.Alphabet = v.Alphabet
+	return nil
+}
+
+// Check (or fix) the characters of a whole sequence
+func (v *Validator) Check(s *seq.Seq) error {
+	a := v.alphabet()
+	if i := a.Index(s.Sequence); i >= 0 {
+		if v.Replace == 0 {
+			return &alphabet.InvalidError{
+				Alphabet: a,
+				Char:     s.Sequence[i],
+				Pos:      i + 1,
+			}
+		}
+		a.Replace(s.Sequence, v.Replace)
+	}
+	s.Alphabet = v.Alphabet
+	return nil
+}
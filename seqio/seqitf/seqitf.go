@@ -15,6 +15,21 @@ type SeqReader interface {
 	IsEOF() bool
 }
 
+// Optional interface of the readers able to fill an existing sequence
+type SeqReuser interface {
+	ReadInto(*seq.Seq) error
+}
+
+// Optional interface of the readers raising non fatal warnings
+type SeqWarner interface {
+	Warning() error
+}
+
+// Optional interface of the readers checking the sequence characters
+type SeqValidator interface {
+	SetValidator(Validator)
+}
+
 // Generic interface to write sequences
 type SeqWriter interface {
 	Write(seq.Seq) error
@@ -0,0 +1,273 @@
+package translate
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/hdevillers/go-seq/alphabet"
+	"github.com/hdevillers/go-seq/seq"
+)
+
+const (
+	Stop    byte = '*'
+	Unknown byte = 'X'
+)
+
+// Translation table structure
+type Table struct {
+	Id     int
+	Name   string
+	aas    [64]byte
+	starts [64]bool
+}
+
+// Nucleotide bit masks (IUPAC codes), bases are indexed in the NCBI order
+// (T, C, A, G)
+var baseMask = func() [256]byte {
+	var m [256]byte
+	codes := map[byte]byte{
+		'T': 1, 'U': 1, 'C': 2, 'A': 4, 'G': 8,
+		'R': 4 | 8, 'Y': 1 | 2, 'S': 2 | 8, 'W': 1 | 4, 'K': 1 | 8, 'M': 2 | 4,
+		'B': 1 | 2 | 8, 'D': 1 | 4 | 8, 'H': 1 | 2 | 4, 'V': 2 | 4 | 8,
+		'N': 1 | 2 | 4 | 8,
+	}
+	for b, v := range codes {
+		m[b] = v
+		m[b+'a'-'A'] = v
+	}
+	return m
+}()
+
+// Ambiguous amino acid codes
+var ambiguousAA = map[string]byte{
+	"DN": 'B',
+	"EQ": 'Z',
+	"IL": 'J',
+}
+
+var tables = func() map[int]*Table {
+	m := make(map[int]*Table)
+	for _, t := range ncbiTables {
+		tab := Table{
+			Id:   t.id,
+			Name: t.name,
+		}
+		for i := 0; i < 64; i++ {
+			tab.aas[i] = t.aas[i]
+			tab.starts[i] = t.starts[i] == 'M'
+		}
+		m[t.id] = &tab
+	}
+	return m
+}()
+
+// Standard genetic code (table 1)
+var Standard = tables[1]
+
+// Get a translation table from its NCBI id
+func GetTable(id int) (*Table, error) {
+	t, ok := tables[id]
+	if !ok {
+		return nil, fmt.Errorf("[TRANSLATE]: Unknown translation table (%d).", id)
+	}
+	return t, nil
+}
+
+// List the available table ids
+func TableIds() []int {
+	ids := make([]int, 0, len(tables))
+	for id := range tables {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// Get the indexes of all the codons matching a (possibly ambiguous) codon
+func expand(codon []byte) []int {
+	if len(codon) < 3 {
+		return nil
+	}
+	m1, m2, m3 := baseMask[codon[0]], baseMask[codon[1]], baseMask[codon[2]]
+	if m1 == 0 || m2 == 0 || m3 == 0 {
+		return nil
+	}
+	var idx []int
+	for i := 0; i < 4; i++ {
+		if m1&(1<<i) == 0 {
+			continue
+		}
+		for j := 0; j < 4; j++ {
+			if m2&(1<<j) == 0 {
+				continue
+			}
+			for k := 0; k < 4; k++ {
+				if m3&(1<<k) != 0 {
+					idx = append(idx, 16*i+4*j+k)
+				}
+			}
+		}
+	}
+	return idx
+}
+
+// Get the index of a non ambiguous codon (-1 otherwise)
+func index(codon []byte) int {
+	if len(codon) < 3 {
+		return -1
+	}
+	idx := 0
+	for _, b := range codon[:3] {
+		switch baseMask[b] {
+		case 1:
+		case 2:
+			idx += 1
+		case 4:
+			idx += 2
+		case 8:
+			idx += 3
+		default:
+			return -1
+		}
+		idx = idx << 2
+	}
+	return idx >> 2
+}
+
+// Translate a codon
+// NOTE: ambiguous codons are translated if all the possible codons give the
+// same amino acid (or B, Z, J), otherwise X is returned (also returned for
+// codons shorter than three bases)
+func (t *Table) Codon(codon []byte) byte {
+	if i := index(codon); i >= 0 {
+		return t.aas[i]
+	}
+	var found [256]bool
+	aas := ""
+	for _, i := range expand(codon) {
+		if aa := t.aas[i]; !found[aa] {
+			found[aa] = true
+			aas += string(aa)
+		}
+	}
+	switch len(aas) {
+	case 0:
+		return Unknown
+	case 1:
+		return aas[0]
+	case 2:
+		if aas[0] > aas[1] {
+			aas = string([]byte{aas[1], aas[0]})
+		}
+		if aa, ok := ambiguousAA[aas]; ok {
+			return aa
+		}
+	}
+	return Unknown
+}
+
+// Return true if the codon is a start codon (all the possible codons if
+// ambiguous), false for codons shorter than three bases
+func (t *Table) IsStart(codon []byte) bool {
+	idx := expand(codon)
+	for _, i := range idx {
+		if !t.starts[i] {
+			return false
+		}
+	}
+	return len(idx) > 0
+}
+
+// Return true if the codon is a stop codon
+func (t *Table) IsStop(codon []byte) bool {
+	return t.Codon(codon) == Stop
+}
+
+// Translation options
+// NOTE: the zero value translates the frame +1 with the standard code
+type Options struct {
+	// Translation table (nil: Standard)
+	Table *Table
+	// Reading frame: 1, 2, 3 or -1, -2, -3 for the reverse strand (0: 1)
+	Frame int
+	// Translate the first codon as M if it is a start codon of the table
+	AltStart bool
+	// Stop the translation at the first stop codon (not included)
+	TrimStop bool
+}
+
+func (o *Options) table() *Table {
+	if o.Table == nil {
+		return Standard
+	}
+	return o.Table
+}
+
+// Translate a nucleotide sequence (the frame is not considered)
+func (o *Options) translate(b []byte) []byte {
+	t := o.table()
+	prot := make([]byte, 0, len(b)/3)
+	for i := 0; i+3 <= len(b); i += 3 {
+		codon := b[i : i+3]
+		aa := t.Codon(codon)
+		if i == 0 && o.AltStart && t.IsStart(codon) {
+			aa = 'M'
+		}
+		if aa == Stop && o.TrimStop {
+			break
+		}
+		prot = append(prot, aa)
+	}
+	return prot
+}
+
+// Translate nucleotides in the selected frame
+func TranslateBytes(b []byte, o Options) ([]byte, error) {
+	frame := o.Frame
+	if frame == 0 {
+		frame = 1
+	}
+	if frame < -3 || frame > 3 {
+		return nil, fmt.Errorf("[TRANSLATE]: Invalid frame (%d).", frame)
+	}
+	if frame < 0 {
+		rc := make([]byte, len(b))
+		copy(rc, b)
+		seq.ReverseComplementBytes(rc)
+		b = rc
+		frame = -frame
+	}
+	if frame-1 >= len(b) {
+		return []byte{}, nil
+	}
+	return o.translate(b[frame-1:]), nil
+}
+
+// Translate a nucleotide sequence in the selected frame
+// NOTE: the protein keeps the ID and the description of the sequence
+func Translate(s seq.Seq, o Options) (seq.Seq, error) {
+	prot, err := TranslateBytes(s.Sequence, o)
+	if err != nil {
+		return seq.Seq{}, err
+	}
+	p := seq.Seq{
+		Id:       s.Id,
+		Desc:     s.Desc,
+		Sequence: prot,
+		Alphabet: alphabet.ProteinExt,
+	}
+	return p, nil
+}
+
+// Translate a nucleotide sequence in the six frames (+1, +2, +3, -1, -2,
+// -3), the frame is added to the protein IDs (e.g., "seq1_-2")
+func SixFrames(s seq.Seq, o Options) []seq.Seq {
+	prots := make([]seq.Seq, 0, 6)
+	for _, f := range []int{1, 2, 3, -1, -2, -3} {
+		o.Frame = f
+		p, _ := Translate(s, o)
+		p.Id = fmt.Sprintf("%s_%+d", s.Id, f)
+		prots = append(prots, p)
+	}
+	return prots
+}
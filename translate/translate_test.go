@@ -0,0 +1,183 @@
+package translate
+
+import (
+	"reflect"
+	"testing"
+)
+
+// NCBI gc.prt (version 4.6) amino acids (ncbieaa) and start codons
+// (sncbieaa) in the order of the codons given by the Base1-3 lines
+const (
+	gcBase1 = "TTTTTTTTTTTTTTTTCCCCCCCCCCCCCCCCAAAAAAAAAAAAAAAAGGGGGGGGGGGGGGGG"
+	gcBase2 = "TTTTCCCCAAAAGGGGTTTTCCCCAAAAGGGGTTTTCCCCAAAAGGGGTTTTCCCCAAAAGGGG"
+	gcBase3 = "TCAGTCAGTCAGTCAGTCAGTCAGTCAGTCAGTCAGTCAGTCAGTCAGTCAGTCAGTCAGTCAG"
+)
+
+var gcPrt = []struct {
+	id          int
+	aas, starts string
+}{
+	{1, "FFLLSSSSYY**CC*WLLLLPPPPHHQQRRRRIIIMTTTTNNKKSSRRVVVVAAAADDEEGGGG",
+		"---M------**--*----M---------------M----------------------------"},
+	{2, "FFLLSSSSYY**CCWWLLLLPPPPHHQQRRRRIIMMTTTTNNKKSS**VVVVAAAADDEEGGGG",
+		"----------**--------------------MMMM----------**---M------------"},
+	{3, "FFLLSSSSYY**CCWWTTTTPPPPHHQQRRRRIIMMTTTTNNKKSSRRVVVVAAAADDEEGGGG",
+		"----------**----------------------MM---------------M------------"},
+	{4, "FFLLSSSSYY**CCWWLLLLPPPPHHQQRRRRIIIMTTTTNNKKSSRRVVVVAAAADDEEGGGG",
+		"--MM------**-------M------------MMMM---------------M------------"},
+	{5, "FFLLSSSSYY**CCWWLLLLPPPPHHQQRRRRIIMMTTTTNNKKSSSSVVVVAAAADDEEGGGG",
+		"---M------**--------------------MMMM---------------M------------"},
+	{6, "FFLLSSSSYYQQCC*WLLLLPPPPHHQQRRRRIIIMTTTTNNKKSSRRVVVVAAAADDEEGGGG",
+		"--------------*--------------------M----------------------------"},
+	{9, "FFLLSSSSYY**CCWWLLLLPPPPHHQQRRRRIIIMTTTTNNNKSSSSVVVVAAAADDEEGGGG",
+		"----------**-----------------------M---------------M------------"},
+	{10, "FFLLSSSSYY**CCCWLLLLPPPPHHQQRRRRIIIMTTTTNNKKSSRRVVVVAAAADDEEGGGG",
+		"----------**-----------------------M----------------------------"},
+	{11, "FFLLSSSSYY**CC*WLLLLPPPPHHQQRRRRIIIMTTTTNNKKSSRRVVVVAAAADDEEGGGG",
+		"---M------**--*----M------------MMMM---------------M------------"},
+	{12, "FFLLSSSSYY**CC*WLLLSPPPPHHQQRRRRIIIMTTTTNNKKSSRRVVVVAAAADDEEGGGG",
+		"----------**--*----M---------------M----------------------------"},
+	{13, "FFLLSSSSYY**CCWWLLLLPPPPHHQQRRRRIIMMTTTTNNKKSSGGVVVVAAAADDEEGGGG",
+		"---M------**----------------------MM---------------M------------"},
+	{14, "FFLLSSSSYYY*CCWWLLLLPPPPHHQQRRRRIIIMTTTTNNNKSSSSVVVVAAAADDEEGGGG",
+		"-----------*-----------------------M----------------------------"},
+	{16, "FFLLSSSSYY*LCC*WLLLLPPPPHHQQRRRRIIIMTTTTNNKKSSRRVVVVAAAADDEEGGGG",
+		"----------*---*--------------------M----------------------------"},
+	{21, "FFLLSSSSYY**CCWWLLLLPPPPHHQQRRRRIIMMTTTTNNNKSSSSVVVVAAAADDEEGGGG",
+		"----------**-----------------------M---------------M------------"},
+	{22, "FFLLSS*SYY*LCC*WLLLLPPPPHHQQRRRRIIIMTTTTNNKKSSRRVVVVAAAADDEEGGGG",
+		"------*---*---*--------------------M----------------------------"},
+	{23, "FF*LSSSSYY**CC*WLLLLPPPPHHQQRRRRIIIMTTTTNNKKSSRRVVVVAAAADDEEGGGG",
+		"--*-------**--*-----------------M--M---------------M------------"},
+	{24, "FFLLSSSSYY**CCWWLLLLPPPPHHQQRRRRIIIMTTTTNNKKSSSKVVVVAAAADDEEGGGG",
+		"---M------**-------M---------------M---------------M------------"},
+	{25, "FFLLSSSSYY**CCGWLLLLPPPPHHQQRRRRIIIMTTTTNNKKSSRRVVVVAAAADDEEGGGG",
+		"---M------**-----------------------M---------------M------------"},
+	{26, "FFLLSSSSYY**CC*WLLLAPPPPHHQQRRRRIIIMTTTTNNKKSSRRVVVVAAAADDEEGGGG",
+		"----------**--*----M---------------M----------------------------"},
+	{27, "FFLLSSSSYYQQCCWWLLLLPPPPHHQQRRRRIIIMTTTTNNKKSSRRVVVVAAAADDEEGGGG",
+		"--------------*--------------------M----------------------------"},
+	{28, "FFLLSSSSYYQQCCWWLLLLPPPPHHQQRRRRIIIMTTTTNNKKSSRRVVVVAAAADDEEGGGG",
+		"----------**--*--------------------M----------------------------"},
+	{29, "FFLLSSSSYYYYCC*WLLLLPPPPHHQQRRRRIIIMTTTTNNKKSSRRVVVVAAAADDEEGGGG",
+		"--------------*--------------------M----------------------------"},
+	{30, "FFLLSSSSYYEECC*WLLLLPPPPHHQQRRRRIIIMTTTTNNKKSSRRVVVVAAAADDEEGGGG",
+		"--------------*--------------------M----------------------------"},
+	{31, "FFLLSSSSYYEECCWWLLLLPPPPHHQQRRRRIIIMTTTTNNKKSSRRVVVVAAAADDEEGGGG",
+		"----------**-----------------------M----------------------------"},
+	{32, "FFLLSSSSYY*WCC*WLLLLPPPPHHQQRRRRIIIMTTTTNNKKSSRRVVVVAAAADDEEGGGG",
+		"---M------*---*----M------------MMMM---------------M------------"},
+	{33, "FFLLSSSSYYY*CCWWLLLLPPPPHHQQRRRRIIIMTTTTNNKKSSSKVVVVAAAADDEEGGGG",
+		"---M-------*-------M---------------M---------------M------------"},
+}
+
+func TestTables(t *testing.T) {
+	var ids []int
+	for _, gc := range gcPrt {
+		ids = append(ids, gc.id)
+		tab, err := GetTable(gc.id)
+		if err != nil {
+			t.Errorf("table %d: %v", gc.id, err)
+			continue
+		}
+		for i := 0; i < 64; i++ {
+			codon := []byte{gcBase1[i], gcBase2[i], gcBase3[i]}
+			if aa := tab.Codon(codon); aa != gc.aas[i] {
+				t.Errorf("table %d: %s = %c, want %c", gc.id, codon, aa, gc.aas[i])
+			}
+			if start := tab.IsStart(codon); start != (gc.starts[i] == 'M') {
+				t.Errorf("table %d: IsStart(%s) = %v", gc.id, codon, start)
+			}
+			if stop := tab.IsStop(codon); stop != (gc.aas[i] == Stop) {
+				t.Errorf("table %d: IsStop(%s) = %v", gc.id, codon, stop)
+			}
+		}
+	}
+	if !reflect.DeepEqual(TableIds(), ids) {
+		t.Errorf("TableIds = %v, want %v", TableIds(), ids)
+	}
+	if tab, _ := GetTable(32); tab.Name != "Balanophoraceae Plastid" {
+		t.Errorf("table 32: %s", tab.Name)
+	}
+	if _, err := GetTable(7); err == nil {
+		t.Errorf("GetTable(7): no error")
+	}
+}
+
+func TestAmbiguousCodons(t *testing.T) {
+	tests := []struct {
+		codon string
+		aa    byte
+	}{
+		{"atg", 'M'},
+		{"UUU", 'F'},
+		{"GCN", 'A'},
+		{"YTR", 'L'},
+		{"TAR", '*'},
+		{"TRA", '*'},
+		{"RAY", 'B'},
+		{"SAR", 'Z'},
+		{"MTT", 'J'},
+		{"AAN", 'X'},
+		{"NNN", 'X'},
+		{"AC-", 'X'},
+		{"", 'X'},
+		{"AT", 'X'},
+		{"ATGC", 'M'},
+	}
+	for _, tt := range tests {
+		if aa := Standard.Codon([]byte(tt.codon)); aa != tt.aa {
+			t.Errorf("Codon(%q) = %c, want %c", tt.codon, aa, tt.aa)
+		}
+	}
+
+	// All the possible codons must be start codons
+	bacterial, _ := GetTable(11)
+	starts := []struct {
+		tab   *Table
+		codon string
+		start bool
+	}{
+		{Standard, "HTG", true},
+		{Standard, "NTG", false},
+		{bacterial, "NTG", true},
+		{bacterial, "ATH", true},
+		{bacterial, "ATN", true},
+		{bacterial, "NTA", false},
+		{Standard, "", false},
+		{Standard, "AT", false},
+	}
+	for _, tt := range starts {
+		if start := tt.tab.IsStart([]byte(tt.codon)); start != tt.start {
+			t.Errorf("table %d: IsStart(%q) = %v", tt.tab.Id, tt.codon, start)
+		}
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	nucl := []byte("ATGAAATAACGTCTGTGA")
+	tests := []struct {
+		o    Options
+		prot string
+	}{
+		{Options{}, "MK*RL*"},
+		{Options{TrimStop: true}, "MK"},
+		{Options{Frame: 2}, "*NNVC"},
+		{Options{Frame: -1}, "SQTLFH"},
+	}
+	for _, tt := range tests {
+		prot, err := TranslateBytes(nucl, tt.o)
+		if err != nil || string(prot) != tt.prot {
+			t.Errorf("TranslateBytes(%+v) = %s, %v, want %s", tt.o, prot, err, tt.prot)
+		}
+	}
+
+	// Alternative start codon
+	prot, _ := TranslateBytes([]byte("TTGAAA"), Options{AltStart: true})
+	if string(prot) != "MK" {
+		t.Errorf("AltStart: %s", prot)
+	}
+	if _, err := TranslateBytes(nucl, Options{Frame: 4}); err == nil {
+		t.Errorf("no error with frame 4")
+	}
+}
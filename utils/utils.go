@@ -1,8 +1,6 @@
 package utils
 
 import (
-	"regexp"
-
 	"github.com/hdevillers/go-seq/seq"
 	"github.com/hdevillers/go-seq/seqio"
 )
@@ -12,11 +10,8 @@ import (
 */
 func LoadSeqInArray(i, f string, a *[]seq.Seq) int {
 	nseq := 0
-	isgz := false
-	if regexp.MustCompile(`\.gz$`).MatchString(i) {
-		isgz = true
-	}
-	reader := seqio.NewReader(i, f, isgz)
+	// NOTE: the compression is detected from the file content
+	reader := seqio.NewCompressedReader(i, f, seqio.AutoCompression)
 	reader.CheckPanic()
 	defer reader.Close()
 
@@ -34,11 +29,8 @@ func LoadSeqInArray(i, f string, a *[]seq.Seq) int {
 */
 func LoadSeqInMap(i, f string, m *map[string]seq.Seq) int {
 	nseq := 0
-	isgz := false
-	if regexp.MustCompile(`\.gz$`).MatchString(i) {
-		isgz = true
-	}
-	reader := seqio.NewReader(i, f, isgz)
+	// NOTE: the compression is detected from the file content
+	reader := seqio.NewCompressedReader(i, f, seqio.AutoCompression)
 	reader.CheckPanic()
 	defer reader.Close()
 